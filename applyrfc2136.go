@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+)
+
+// tsigFudge is the allowed clock difference of TSIG signed messages.
+const tsigFudge = 300
+
+// dnsLookup returns a libdctlint.TXTLookup that queries server.
+func dnsLookup(client *dns.Client, server string) libdctlint.TXTLookup {
+	return func(fqdn string) ([][]string, error) {
+		query := new(dns.Msg)
+		query.SetQuestion(fqdn, dns.TypeTXT)
+		reply, _, err := client.Exchange(query, server)
+		if err != nil {
+			return nil, err
+		}
+		if reply.Rcode != dns.RcodeSuccess && reply.Rcode != dns.RcodeNameError {
+			return nil, fmt.Errorf("query failed: %s", dns.RcodeToString[reply.Rcode])
+		}
+		var records [][]string
+		for _, rr := range reply.Answer {
+			if txt, ok := rr.(*dns.TXT); ok && strings.EqualFold(txt.Hdr.Name, fqdn) {
+				records = append(records, txt.Txt)
+			}
+		}
+		return records, nil
+	}
+}
+
+// applyRFC2136Command applies a template to a nameserver with an RFC 2136
+// DNS UPDATE.
+func applyRFC2136Command(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("apply-rfc2136", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s apply-rfc2136 [options] <template.json>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	vars := make(variableFlags)
+	server := fs.String("server", "127.0.0.1:53", "nameserver address to send the update to")
+	zone := fs.String("zone", "", "zone to update, default is the domain")
+	domain := fs.String("domain", "", "domain parameter")
+	host := fs.String("host", "", "host parameter")
+	groups := fs.String("group", "", "comma separated list of groupId values to apply")
+	fs.Var(vars, "var", "variable value as name=value, can be repeated")
+	tsigName := fs.String("tsig-name", "", "TSIG key name, update is signed when set")
+	tsigSecret := fs.String("tsig-secret", "", "base64 TSIG key secret")
+	tsigAlg := fs.String("tsig-alg", "hmac-sha256", "TSIG algorithm")
+	dryRun := fs.Bool("dry-run", false, "print the update message instead of sending it, also when there are errors, existing TXT records are still queried from -server")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() != 1 || (*tsigName == "") != (*tsigSecret == "") {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	template, exitVal := readTemplateFile(conf, fs.Arg(0))
+	if exitVal != exitvals.CheckOK {
+		return exitVal
+	}
+
+	client := new(dns.Client)
+	if *tsigName != "" {
+		client.TsigSecret = map[string]string{dns.Fqdn(*tsigName): *tsigSecret}
+	}
+
+	params := libdctlint.ApplyParams{
+		Domain:    *domain,
+		Host:      *host,
+		GroupIDs:  splitList(*groups),
+		Variables: vars,
+	}
+	msg, exitVal := conf.BuildUpdate(template, params, *zone, dnsLookup(client, *server))
+	if msg == nil {
+		return tolerate(conf.GetToleration(), exitVal)
+	}
+	if *tsigName != "" {
+		msg.SetTsig(dns.Fqdn(*tsigName), dns.Fqdn(*tsigAlg), tsigFudge, time.Now().Unix())
+	}
+
+	if *dryRun {
+		if _, err := fmt.Println(msg.String()); err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+			exitVal |= exitvals.CheckError
+		}
+		return tolerate(conf.GetToleration(), exitVal)
+	}
+
+	// an update built with errors, such as failed TXT lookups, would
+	// leave conflicting records in the zone
+	if exitvals.CheckError <= exitVal {
+		log.Error().Str("server", *server).Msg("update not sent because of errors, use -dry-run to see it")
+		return tolerate(conf.GetToleration(), exitVal)
+	}
+
+	reply, _, err := client.Exchange(msg, *server)
+	switch {
+	case err != nil:
+		log.Error().Str("server", *server).Err(err).EmbedObject(internal.DCTL2027).Msg("")
+		exitVal |= exitvals.CheckError
+	case reply.Rcode != dns.RcodeSuccess:
+		log.Error().Str("server", *server).Str("rcode", dns.RcodeToString[reply.Rcode]).EmbedObject(internal.DCTL2027).Msg("")
+		exitVal |= exitvals.CheckError
+	default:
+		log.Info().Str("server", *server).Str("zone", msg.Question[0].Name).Int("updates", len(msg.Ns)).Msg("update applied")
+	}
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
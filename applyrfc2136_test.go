@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+
+	"github.com/miekg/dns"
+)
+
+// testServer is an in-process nameserver that answers TXT queries from
+// zone and records the update sections it receives.
+type testServer struct {
+	zone    map[string][][]string
+	rcode   int
+	mu      sync.Mutex
+	updates [][]dns.RR
+}
+
+func (ts *testServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	reply := new(dns.Msg)
+	reply.SetRcode(req, ts.rcode)
+	if req.Opcode == dns.OpcodeUpdate {
+		ts.mu.Lock()
+		ts.updates = append(ts.updates, req.Ns)
+		ts.mu.Unlock()
+	} else if ts.rcode == dns.RcodeSuccess {
+		q := req.Question[0]
+		for _, txt := range ts.zone[q.Name] {
+			reply.Answer = append(reply.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 300},
+				Txt: txt,
+			})
+		}
+	}
+	_ = w.WriteMsg(reply)
+}
+
+// received returns the update sections received so far.
+func (ts *testServer) received() [][]dns.RR {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return slices.Clone(ts.updates)
+}
+
+// start runs the server on a local UDP port and returns its address.
+func (ts *testServer) start(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &dns.Server{
+		PacketConn:        pc,
+		Handler:           ts,
+		NotifyStartedFunc: func() { close(started) },
+		// the default rejects updates
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+	go func() { _ = srv.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func TestApplyRFC2136(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "template.json")
+	err := os.WriteFile(template, []byte(`{
+	"providerId": "example.net",
+	"serviceId": "test",
+	"records": [
+		{"type": "SPFM", "host": "@", "spfRules": "include:c.example.net", "ttl": 300}
+	]
+}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &testServer{zone: map[string][][]string{
+		"example.com.": {{"v=spf1 include:a.example.net", " include:b.example.net ~all"}},
+	}}
+	exitVal := applyRFC2136Command([]string{"-server", ts.start(t), "-domain", "example.com", "-loglevel", "warn", template})
+	if exitVal != exitvals.CheckOK {
+		t.Fatalf("exit %v", exitVal)
+	}
+	updates := ts.received()
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want 1", len(updates))
+	}
+	var got []string
+	for _, rr := range updates[0] {
+		got = append(got, rr.String())
+	}
+	want := []string{
+		"example.com.\t0\tNONE\tTXT\t\"v=spf1 include:a.example.net\" \" include:b.example.net ~all\"",
+		"example.com.\t0\tCLASS255\tCNAME\t",
+		"example.com.\t300\tIN\tTXT\t\"v=spf1 include:a.example.net include:b.example.net include:c.example.net ~all\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("update\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// a failed TXT query must not send an update
+	failing := &testServer{rcode: dns.RcodeServerFailure}
+	exitVal = applyRFC2136Command([]string{"-server", failing.start(t), "-domain", "example.com", "-loglevel", "fatal", template})
+	if exitVal&exitvals.CheckError == 0 {
+		t.Errorf("exit %v, want error", exitVal)
+	}
+	if updates := failing.received(); len(updates) != 0 {
+		t.Errorf("got %d updates, want none", len(updates))
+	}
+}
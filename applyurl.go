@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// applyURLCommand builds, and optionally signs, a synchronous apply url.
+func applyURLCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("apply-url", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s apply-url [options] <template.json>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	vars := make(variableFlags)
+	base := fs.String("base", "", "DNS provider urlSyncUX to prefix the apply path with")
+	domain := fs.String("domain", "", "domain parameter")
+	host := fs.String("host", "", "host parameter")
+	groups := fs.String("group", "", "comma separated list of groupId values to apply")
+	fs.Var(vars, "var", "variable value as name=value, can be repeated")
+	redirectURI := fs.String("redirect-uri", "", "redirect_uri parameter")
+	state := fs.String("state", "", "state parameter")
+	privKey := fs.String("privkey", "", "PEM private key file to sign the url with")
+	key := fs.String("key", "", "key parameter, the host of public key under syncPubKeyDomain")
+	pubKey := fs.String("pubkey", "", "published public key to compare the private key against")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	var signing libdctlint.ApplySigning
+	for _, kf := range []struct {
+		file string
+		dest *[]byte
+	}{
+		{*privKey, &signing.PrivateKey},
+		{*pubKey, &signing.PublicKey},
+	} {
+		if kf.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(kf.file)
+		if err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+			return exitvals.CheckError
+		}
+		*kf.dest = data
+	}
+	signing.Key = *key
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	template, exitVal := readTemplateFile(conf, fs.Arg(0))
+	if exitVal != exitvals.CheckOK {
+		return exitVal
+	}
+
+	params := libdctlint.ApplyParams{
+		Domain:      *domain,
+		Host:        *host,
+		GroupIDs:    splitList(*groups),
+		Variables:   vars,
+		RedirectURI: *redirectURI,
+		State:       *state,
+	}
+	applyURL, exitVal := conf.BuildApplyURL(template, *base, params, signing)
+	_, err := fmt.Println(applyURL)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+		exitVal |= exitvals.CheckError
+	}
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// catalogFinish makes conf catalog checked templates, and returns a
+// runMode finish function that runs the next finish function, writes the
+// catalog, and compares it with the previous catalog when prevFile is set.
+func catalogFinish(conf *libdctlint.Conf, fileName, prevFile string, next func() exitvals.CheckSeverity) func() exitvals.CheckSeverity {
+	catalog := libdctlint.NewCatalog()
+	conf.SetCatalog(catalog)
+
+	return func() exitvals.CheckSeverity {
+		exitVal := exitvals.CheckOK
+		if next != nil {
+			exitVal |= next()
+		}
+		if prevFile != "" {
+			exitVal |= reportCatalogDiff(catalog, prevFile)
+		}
+
+		f, err := os.Create(fileName)
+		if err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+			return exitVal | exitvals.CheckError
+		}
+		err = catalog.Write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+			return exitVal | exitvals.CheckError
+		}
+		log.Debug().Str("catalog", fileName).Int("templates", len(catalog.Templates)).Msg("catalog written")
+		return exitVal
+	}
+}
+
+// reportCatalogDiff logs services added and removed since the previous
+// catalog.
+func reportCatalogDiff(catalog *libdctlint.Catalog, prevFile string) exitvals.CheckSeverity {
+	f, err := os.Open(prevFile)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		return exitvals.CheckError
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close file")
+		}
+	}()
+	prev, err := libdctlint.ReadCatalog(f)
+	if err != nil {
+		log.Error().Str("catalog", prevFile).Err(err).EmbedObject(internal.DCTL0003).Msg("")
+		return exitvals.CheckError
+	}
+
+	diff := catalog.Diff(prev)
+	for _, id := range diff.Added {
+		log.Info().Str("service", id).Msg("service added")
+	}
+	for _, id := range diff.Removed {
+		log.Info().Str("service", id).Msg("service removed")
+	}
+	return exitvals.CheckOK
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// checkApplyCommand validates an apply url against the template it
+// refers to. When several templates are given the one matching the url
+// providerId and serviceId is used.
+func checkApplyCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("check-apply", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s check-apply [options] <template.json> [...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	applyURL := fs.String("url", "", "apply url, or the query string of it")
+	pubKey := fs.String("pubkey", "", "verify signature with PEM public key or syncPubKeyDomain TXT record content")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() < 1 || *applyURL == "" {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	var keyData []byte
+	if *pubKey != "" {
+		var err error
+		keyData, err = os.ReadFile(*pubKey)
+		if err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+			return exitvals.CheckError
+		}
+	}
+
+	// Parse errors are reported by CheckApplyRequest()
+	req, _ := libdctlint.ParseApplyRequest(*applyURL)
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	var selected string
+	for _, arg := range fs.Args() {
+		template, exitVal := readTemplateFile(conf, arg)
+		if exitVal != exitvals.CheckOK {
+			return exitVal
+		}
+		if template.ProviderID == req.ProviderID && template.ServiceID == req.ServiceID {
+			selected = arg
+			break
+		}
+	}
+	if selected == "" {
+		if 1 < fs.NArg() {
+			log.Warn().Str("providerId", req.ProviderID).Str("serviceId", req.ServiceID).
+				Msg("no template matches apply url, using the first template")
+		}
+		selected = fs.Arg(0)
+	}
+
+	template, exitVal := readTemplateFile(conf, selected)
+	if exitVal != exitvals.CheckOK {
+		return exitVal
+	}
+	exitVal = conf.CheckApplyRequest(template, *applyURL, keyData)
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// compatRow is a template row in the compatibility matrix.
+type compatRow struct {
+	Template   string                    `json:"template"`
+	ProviderID string                    `json:"providerId"`
+	ServiceID  string                    `json:"serviceId"`
+	Results    []libdctlint.CompatResult `json:"results"`
+}
+
+// newCompatMode collects template compatibility results, and writes the
+// matrix when all templates are processed.
+func newCompatMode(conf *libdctlint.Conf, format string) runMode {
+	if format != "markdown" && format != "json" {
+		log.Fatal().Str("format", format).Msg("unknown report format")
+	}
+	var rows []compatRow
+
+	return runMode{
+		check: func(f *bufio.Reader) exitvals.CheckSeverity {
+			template, exitVal := conf.ReadTemplate(f)
+			if exitVal != exitvals.CheckOK {
+				return exitVal
+			}
+			rows = append(rows, compatRow{
+				Template:   conf.GetFilename(),
+				ProviderID: template.ProviderID,
+				ServiceID:  template.ServiceID,
+				Results:    conf.CheckCompatibility(template),
+			})
+			return exitvals.CheckOK
+		},
+		finish: func() exitvals.CheckSeverity {
+			var err error
+			if format == "json" {
+				err = writeCompatJSON(os.Stdout, rows)
+			} else {
+				err = writeCompatMarkdown(os.Stdout, rows)
+			}
+			if err != nil {
+				log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+				return exitvals.CheckError
+			}
+			return exitvals.CheckOK
+		},
+	}
+}
+
+func writeCompatJSON(w io.Writer, rows []compatRow) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(rows)
+}
+
+func writeCompatMarkdown(w io.Writer, rows []compatRow) error {
+	profiles := libdctlint.ProfileNames()
+	var sb strings.Builder
+
+	sb.WriteString("| template |")
+	for _, name := range profiles {
+		fmt.Fprintf(&sb, " %s |", name)
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", len(profiles)))
+	sb.WriteString("\n")
+	for _, row := range rows {
+		fmt.Fprintf(&sb, "| %s/%s |", row.ProviderID, row.ServiceID)
+		for _, result := range row.Results {
+			codes := make([]string, 0, len(result.Reasons))
+			for _, reason := range result.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			if len(codes) == 0 {
+				fmt.Fprintf(&sb, " %s |", result.Status)
+			} else {
+				fmt.Fprintf(&sb, " %s: %s |", result.Status, strings.Join(codes, " "))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n### Reasons\n\n")
+	for _, row := range rows {
+		for _, result := range row.Results {
+			for _, reason := range result.Reasons {
+				fmt.Fprintf(&sb, "- %s/%s %s: %s %s %s", row.ProviderID, row.ServiceID,
+					result.Profile, reason.Level, reason.Code, reason.Note)
+				if 0 < reason.Record {
+					fmt.Fprintf(&sb, " (record %d)", reason.Record)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
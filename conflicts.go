@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// conflictMatrix is the template by template conflict matrix. Cells list
+// the DCTL codes of conflicts between the row and column templates, and
+// the matrix is symmetric.
+type conflictMatrix struct {
+	Templates []string                    `json:"templates"`
+	Matrix    [][][]string                `json:"matrix"`
+	Conflicts []libdctlint.ConflictResult `json:"conflicts"`
+}
+
+func newConflictMatrix(templates []internal.Template, results []libdctlint.ConflictResult) conflictMatrix {
+	m := conflictMatrix{
+		Templates: make([]string, len(templates)),
+		Matrix:    make([][][]string, len(templates)),
+		Conflicts: results,
+	}
+	if m.Conflicts == nil {
+		m.Conflicts = []libdctlint.ConflictResult{}
+	}
+	for i, template := range templates {
+		m.Templates[i] = template.ProviderID + "/" + template.ServiceID
+		m.Matrix[i] = make([][]string, len(templates))
+		for j := range templates {
+			m.Matrix[i][j] = []string{}
+		}
+	}
+	for _, result := range results {
+		var codes []string
+		for _, conflict := range result.Conflicts {
+			if !slices.Contains(codes, conflict.Code) {
+				codes = append(codes, conflict.Code)
+			}
+		}
+		slices.Sort(codes)
+		m.Matrix[result.Index][result.OtherIndex] = codes
+		m.Matrix[result.OtherIndex][result.Index] = codes
+	}
+	return m
+}
+
+// newConflictsMode collects templates, and writes the conflict matrix of
+// every template pair when all templates are read.
+func newConflictsMode(conf *libdctlint.Conf, format string) runMode {
+	if format != "markdown" && format != "json" {
+		log.Fatal().Str("format", format).Msg("unknown report format")
+	}
+	var templates []internal.Template
+
+	return runMode{
+		check: func(f *bufio.Reader) exitvals.CheckSeverity {
+			template, exitVal := conf.ReadTemplate(f)
+			if exitVal != exitvals.CheckOK {
+				return exitVal
+			}
+			templates = append(templates, template)
+			return exitvals.CheckOK
+		},
+		finish: func() exitvals.CheckSeverity {
+			results, exitVal := conf.CheckConflicts(templates)
+			m := newConflictMatrix(templates, results)
+			var err error
+			if format == "json" {
+				err = writeConflictsJSON(os.Stdout, m)
+			} else {
+				err = writeConflictsMarkdown(os.Stdout, m)
+			}
+			if err != nil {
+				log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+				return exitVal | exitvals.CheckError
+			}
+			return exitVal
+		},
+	}
+}
+
+func writeConflictsJSON(w io.Writer, m conflictMatrix) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(m)
+}
+
+// writeConflictsMarkdown writes the conflict matrix with numbered template
+// columns, followed by a list of the conflicting records.
+func writeConflictsMarkdown(w io.Writer, m conflictMatrix) error {
+	var sb strings.Builder
+
+	sb.WriteString("| template |")
+	for i := range m.Templates {
+		fmt.Fprintf(&sb, " %d |", i+1)
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", len(m.Templates)))
+	sb.WriteString("\n")
+	for i, row := range m.Matrix {
+		fmt.Fprintf(&sb, "| %d. %s |", i+1, m.Templates[i])
+		for j, codes := range row {
+			if i == j {
+				sb.WriteString(" - |")
+			} else {
+				fmt.Fprintf(&sb, " %s |", strings.Join(codes, " "))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n### Conflicts\n\n")
+	for _, result := range m.Conflicts {
+		for _, conflict := range result.Conflicts {
+			fmt.Fprintf(&sb, "- %s record %d and %s record %d at %s: %s %s %s\n",
+				result.Template, conflict.Record, result.Other, conflict.OtherRecord,
+				conflict.Host, conflict.Level, conflict.Code, conflict.Note)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+)
+
+// exportCommand applies a template, and writes the records in an
+// infrastructure as code format.
+func exportCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s export [options] <template.json>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	vars := make(variableFlags)
+	format := fs.String("format", string(libdctlint.ExportTerraform), "export format: "+strings.Join(libdctlint.ExportFormats(), " "))
+	domain := fs.String("domain", "", "domain parameter")
+	host := fs.String("host", "", "host parameter")
+	groups := fs.String("group", "", "comma separated list of groupId values to apply")
+	fs.Var(vars, "var", "variable value as name=value, can be repeated")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() != 1 || !slices.Contains(libdctlint.ExportFormats(), *format) {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	template, exitVal := readTemplateFile(conf, fs.Arg(0))
+	if exitVal != exitvals.CheckOK {
+		return exitVal
+	}
+
+	params := libdctlint.ApplyParams{
+		Domain:    *domain,
+		Host:      *host,
+		GroupIDs:  splitList(*groups),
+		Variables: vars,
+	}
+	exitVal = conf.ExportTemplate(os.Stdout, template, params, libdctlint.ExportFormat(*format))
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
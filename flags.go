@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// variableFlags collects repeated -var name=value options.
+type variableFlags map[string]string
+
+func (v variableFlags) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v variableFlags) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got '%s'", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// splitList splits a comma separated option value, empty input results
+// to an empty list.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// lintFlags are the options that decide how templates are checked. They
+// are shared by normal linting and the commands that lint templates, so
+// that both give the same results.
+type lintFlags struct {
+	checkLogos  *bool
+	cloudflare  *bool
+	profileName *string
+	profileFile *string
+	mergeOrFail *bool
+	loglevel    *string
+	toleration  *string
+	varLength   *uint
+}
+
+func addLintFlags(fs *flag.FlagSet) *lintFlags {
+	return &lintFlags{
+		checkLogos:  fs.Bool("logos", false, "check logo urls are reachable (requires network)"),
+		cloudflare:  fs.Bool("cloudflare", false, "use Cloudflare specific template rules, same as -profile cloudflare"),
+		profileName: fs.String("profile", "", "DNS provider profile: "+strings.Join(libdctlint.ProfileNames(), " ")),
+		profileFile: fs.String("profile-file", "", "load DNS provider profile from json or yaml file, and use it unless -profile is set"),
+		mergeOrFail: fs.Bool("merge-or-fail", false, "the https://github.com/Domain-Connect/Templates auto-merge condition"),
+		loglevel:    fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace"),
+		toleration:  fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none"),
+		varLength:   fs.Uint("varlen", libdctlint.DefaultVariableLength, "worst case length of a variable, host, or domain value in name length checks"),
+	}
+}
+
+// conf sets the loglevel, and returns a Conf configured with the lint
+// options. Invalid options are fatal.
+func (lf *lintFlags) conf() *libdctlint.Conf {
+	// Override loglevel and other options when --merge-or-fail is in use
+	if *lf.mergeOrFail {
+		*lf.checkLogos = true
+		*lf.toleration = "debug"
+		*lf.loglevel = "info"
+	}
+
+	// Runtime init
+	internal.SetLoglevel(*lf.loglevel)
+
+	if *lf.cloudflare && (*lf.profileName != "" || *lf.profileFile != "") {
+		log.Fatal().Msg("-cloudflare cannot be used with -profile or -profile-file")
+	}
+	if *lf.profileFile != "" {
+		p, err := libdctlint.LoadProfileFile(*lf.profileFile)
+		if err == nil {
+			err = libdctlint.RegisterProfile(p)
+		}
+		if err != nil {
+			log.Fatal().Err(err).EmbedObject(internal.DCTL0010).Msg("")
+		}
+		if *lf.profileName == "" {
+			*lf.profileName = p.Name
+		}
+	}
+	if *lf.cloudflare {
+		*lf.profileName = "cloudflare"
+	}
+	var profile *libdctlint.Profile
+	if *lf.profileName != "" {
+		var ok bool
+		profile, ok = libdctlint.LookupProfile(*lf.profileName)
+		if !ok {
+			log.Fatal().Str("profile", *lf.profileName).Strs("known", libdctlint.ProfileNames()).Msg("unknown DNS provider profile")
+		}
+	}
+
+	return libdctlint.NewConf().
+		SetCheckLogos(*lf.checkLogos).
+		SetProfile(profile).
+		SetMergeOrFail(*lf.mergeOrFail).
+		SetToleration(*lf.toleration).
+		SetVariableLength(*lf.varLength)
+}
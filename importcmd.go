@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// importCommand creates a template of records in a zone fragment or a
+// json RRset, writes it to <providerId>.<serviceId>.json, and checks it.
+func importCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s import [options] <zone-fragment|rrset.json>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	vars := make(variableFlags)
+	domain := fs.String("domain", "", "sample domain the records are from")
+	providerID := fs.String("provider-id", "", "providerId of the template")
+	providerName := fs.String("provider-name", "", "providerName of the template, default is providerId")
+	serviceID := fs.String("service-id", "", "serviceId of the template")
+	serviceName := fs.String("service-name", "", "serviceName of the template, default is serviceId")
+	fs.Var(vars, "var", "variable and its sample value as name=value, can be repeated")
+	outDir := fs.String("out", ".", "directory to write the template to")
+	indent := fs.Uint("indent", 4, "number of spaces in an indent step of the template json")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() != 1 || *domain == "" || *providerID == "" || *serviceID == "" {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		return exitvals.CheckError
+	}
+	var rrs []internal.ResourceRecord
+	if strings.HasSuffix(fs.Arg(0), ".json") {
+		rrs, err = libdctlint.ParseRRSetJSON(bytes.NewReader(data))
+	} else {
+		rrs, err = libdctlint.ParseZoneFragment(bytes.NewReader(data), *domain)
+	}
+	if err != nil {
+		log.Error().Str("file", fs.Arg(0)).Err(err).EmbedObject(internal.DCTL0003).Msg("")
+		return exitvals.CheckError
+	}
+
+	conf := libdctlint.NewConf().SetToleration(*toleration).SetIndent(*indent).SetFilename(fs.Arg(0))
+	template, exitVal := conf.ImportTemplate(rrs, libdctlint.ImportParams{
+		ProviderID:   *providerID,
+		ProviderName: *providerName,
+		ServiceID:    *serviceID,
+		ServiceName:  *serviceName,
+		Domain:       *domain,
+		Variables:    vars,
+	})
+	if exitvals.CheckError <= exitVal {
+		return exitVal
+	}
+
+	pretty, err := libdctlint.FormatTemplate(template, *indent)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0003).Msg("")
+		return exitVal | exitvals.CheckError
+	}
+	fileName := filepath.Join(*outDir, libdctlint.TemplateFileName(template))
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+		return exitVal | exitvals.CheckError
+	}
+	_, err = f.Write(pretty)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+		return exitVal | exitvals.CheckError
+	}
+	log.Info().Str("template", fileName).Int("records", len(template.Records)).Msg("template written")
+
+	exitVal |= conf.SetFilename(fileName).CheckTemplate(bufio.NewReader(bytes.NewReader(pretty)))
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
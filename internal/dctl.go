@@ -14,8 +14,14 @@ type DCTL uint16
 // DCTL code reservations
 // 0000         unused
 // 0001 - 0999  operating system and library errors
-// 1000 - 3999  domain connect specific messages
+// 1000 - 3999  domain connect specific messages, of which
+// 2000 - 2199  apply requests and signatures
+// 2200 - 2299  template test cases
+// 3000 - 3019  conflicts between templates applied to the same domain
+// 3020 - 3039  consistency of templates of the same provider
+// 4000 - 4199  settings and discovery document messages
 // 5000 - 5200  cloudflare messages
+// 6000 - 6099  DNS provider profile file messages
 const (
 	DCTL0001 DCTL = 1
 	DCTL0002 DCTL = 2
@@ -26,6 +32,7 @@ const (
 	DCTL0007 DCTL = 7
 	DCTL0008 DCTL = 8
 	DCTL0009 DCTL = 9
+	DCTL0010 DCTL = 10
 
 	DCTL1000 DCTL = 1000
 	DCTL1001 DCTL = 1001
@@ -68,6 +75,107 @@ const (
 	DCTL1038 DCTL = 1038
 	DCTL1039 DCTL = 1039
 	DCTL1040 DCTL = 1040
+	DCTL1041 DCTL = 1041
+	DCTL1042 DCTL = 1042
+	DCTL1043 DCTL = 1043
+	DCTL1044 DCTL = 1044
+	DCTL1045 DCTL = 1045
+	DCTL1046 DCTL = 1046
+	DCTL1047 DCTL = 1047
+	DCTL1048 DCTL = 1048
+	DCTL1049 DCTL = 1049
+	DCTL1050 DCTL = 1050
+	DCTL1051 DCTL = 1051
+	DCTL1052 DCTL = 1052
+	DCTL1053 DCTL = 1053
+	DCTL1054 DCTL = 1054
+	DCTL1055 DCTL = 1055
+	DCTL1056 DCTL = 1056
+	DCTL1057 DCTL = 1057
+	DCTL1058 DCTL = 1058
+	DCTL1059 DCTL = 1059
+	DCTL1060 DCTL = 1060
+	DCTL1061 DCTL = 1061
+	DCTL1062 DCTL = 1062
+	DCTL1063 DCTL = 1063
+	DCTL1064 DCTL = 1064
+	DCTL1065 DCTL = 1065
+	DCTL1066 DCTL = 1066
+	DCTL1067 DCTL = 1067
+	DCTL1068 DCTL = 1068
+	DCTL1069 DCTL = 1069
+	DCTL1070 DCTL = 1070
+	DCTL1071 DCTL = 1071
+	DCTL1072 DCTL = 1072
+	DCTL1073 DCTL = 1073
+	DCTL1074 DCTL = 1074
+	DCTL1075 DCTL = 1075
+	DCTL1076 DCTL = 1076
+	DCTL1077 DCTL = 1077
+	DCTL1078 DCTL = 1078
+	DCTL1079 DCTL = 1079
+	DCTL1080 DCTL = 1080
+	DCTL1081 DCTL = 1081
+	DCTL1082 DCTL = 1082
+	DCTL1083 DCTL = 1083
+	DCTL1084 DCTL = 1084
+
+	DCTL2000 DCTL = 2000
+	DCTL2001 DCTL = 2001
+	DCTL2002 DCTL = 2002
+	DCTL2003 DCTL = 2003
+	DCTL2004 DCTL = 2004
+	DCTL2005 DCTL = 2005
+	DCTL2006 DCTL = 2006
+	DCTL2007 DCTL = 2007
+	DCTL2008 DCTL = 2008
+	DCTL2009 DCTL = 2009
+	DCTL2010 DCTL = 2010
+	DCTL2011 DCTL = 2011
+	DCTL2012 DCTL = 2012
+	DCTL2013 DCTL = 2013
+	DCTL2014 DCTL = 2014
+	DCTL2015 DCTL = 2015
+	DCTL2016 DCTL = 2016
+	DCTL2017 DCTL = 2017
+	DCTL2018 DCTL = 2018
+	DCTL2019 DCTL = 2019
+	DCTL2020 DCTL = 2020
+	DCTL2021 DCTL = 2021
+	DCTL2022 DCTL = 2022
+	DCTL2023 DCTL = 2023
+	DCTL2024 DCTL = 2024
+	DCTL2025 DCTL = 2025
+	DCTL2026 DCTL = 2026
+	DCTL2027 DCTL = 2027
+	DCTL2028 DCTL = 2028
+
+	DCTL2200 DCTL = 2200
+	DCTL2201 DCTL = 2201
+	DCTL2202 DCTL = 2202
+	DCTL2203 DCTL = 2203
+	DCTL2204 DCTL = 2204
+	DCTL2205 DCTL = 2205
+
+	DCTL3000 DCTL = 3000
+	DCTL3001 DCTL = 3001
+	DCTL3002 DCTL = 3002
+	DCTL3003 DCTL = 3003
+	DCTL3004 DCTL = 3004
+
+	DCTL3020 DCTL = 3020
+	DCTL3021 DCTL = 3021
+	DCTL3022 DCTL = 3022
+
+	DCTL4000 DCTL = 4000
+	DCTL4001 DCTL = 4001
+	DCTL4002 DCTL = 4002
+	DCTL4003 DCTL = 4003
+	DCTL4004 DCTL = 4004
+	DCTL4005 DCTL = 4005
+	DCTL4006 DCTL = 4006
+	DCTL4007 DCTL = 4007
+	DCTL4008 DCTL = 4008
 
 	DCTL5000 DCTL = 5000
 	DCTL5001 DCTL = 5001
@@ -81,6 +189,20 @@ const (
 	DCTL5009 DCTL = 5009
 	DCTL5010 DCTL = 5010
 	DCTL5011 DCTL = 5011
+
+	DCTL6000 DCTL = 6000
+	DCTL6001 DCTL = 6001
+	DCTL6002 DCTL = 6002
+	DCTL6003 DCTL = 6003
+	DCTL6004 DCTL = 6004
+	DCTL6005 DCTL = 6005
+	DCTL6006 DCTL = 6006
+	DCTL6007 DCTL = 6007
+	DCTL6008 DCTL = 6008
+	DCTL6009 DCTL = 6009
+	DCTL6010 DCTL = 6010
+	DCTL6011 DCTL = 6011
+	DCTL6012 DCTL = 6012
 )
 
 // DCTL descriptions
@@ -95,6 +217,7 @@ var dctlToString = map[DCTL]string{
 	DCTL0007: "struct json tag missing",
 	DCTL0008: "required field is missing",
 	DCTL0009: "unnecessary field found",
+	DCTL0010: "cannot load DNS provider profile",
 
 	// domain connect specific messages
 	DCTL1000: "ttl value exceeds maximum",
@@ -138,6 +261,112 @@ var dctlToString = map[DCTL]string{
 	DCTL1038: "APEXCNAME and REDIRxxx records are not widely supported",
 	DCTL1039: "all records use the same variable as suffix, consider using host parameter instead",
 	DCTL1040: "bare variables in host or pointsTo record field",
+	DCTL1041: "invalid CAA record data",
+	DCTL1042: "invalid TLSA or SMIMEA record data",
+	DCTL1043: "invalid SVCB or HTTPS record data",
+	DCTL1044: "invalid URI record data",
+	DCTL1045: "invalid SSHFP record data",
+	DCTL1046: "invalid DS record data",
+	DCTL1047: "invalid DMARC tag",
+	DCTL1048: "missing required DMARC tag",
+	DCTL1049: "invalid DKIM key record tag",
+	DCTL1050: "missing required DKIM key record tag",
+	DCTL1051: "invalid MTA-STS tag",
+	DCTL1052: "missing required MTA-STS tag",
+	DCTL1053: "invalid TLS-RPT tag",
+	DCTL1054: "missing required TLS-RPT tag",
+	DCTL1055: "invalid BIMI tag",
+	DCTL1056: "missing required BIMI tag",
+	DCTL1057: "unknown tag in TXT record",
+	DCTL1058: "DKIM public key cannot be parsed",
+	DCTL1059: "DKIM RSA key is shorter than 2048 bits",
+	DCTL1060: "DKIM k= tag does not match the public key type",
+	DCTL1061: "DKIM public key is split by whitespace or quotes",
+	DCTL1062: "label is longer than 63 octets",
+	DCTL1063: "name can be longer than 253 octets when variables are substituted",
+	DCTL1064: "label can be longer than 63 octets when variables are substituted",
+	DCTL1065: "target is not a valid hostname",
+	DCTL1066: "target must be a hostname, not an IP address",
+	DCTL1067: "null MX record must have priority 0",
+	DCTL1068: "target '.' tells the service is not available",
+	DCTL1069: "REDIR target is not a valid url",
+	DCTL1070: "REDIR target scheme must be http or https",
+	DCTL1071: "REDIR target host is not a valid domain name",
+	DCTL1072: "REDIR target host is a variable, the record is an open redirect",
+	DCTL1073: "REDIR record conflicts with A, AAAA, or CNAME record on the same host",
+	DCTL1074: "record is below an NS delegation and will not be served",
+	DCTL1075: "wildcard does not match names that are defined explicitly",
+	DCTL1076: "CNAME record at a name that has child records",
+	DCTL1077: "CNAME conflicts with a record of another group when the groups are applied together",
+	DCTL1078: "the same record is in another group, applying both groups creates a duplicate",
+	DCTL1079: "all records of the group are essential OnApply",
+	DCTL1080: "group cannot be applied alone",
+	DCTL1081: "records at the same host and type have different essential values in different groups",
+	DCTL1082: "multiInstance record is deleted when the template is applied again",
+	DCTL1083: "multiInstance record does not change between instances and is rewritten on every apply",
+	DCTL1084: "multiInstance CNAME conflicts with a record of another instance",
+
+	// apply request and signature messages
+	DCTL2000: "template does not have syncPubKeyDomain, signatures are not verified",
+	DCTL2001: "apply query string cannot be parsed",
+	DCTL2002: "sig parameter is missing",
+	DCTL2003: "key parameter is missing",
+	DCTL2004: "sig parameter is not valid base64",
+	DCTL2005: "public key cannot be parsed",
+	DCTL2006: "public key is not an RSA key",
+	DCTL2007: "signature verification failed",
+	DCTL2008: "sig and key should be the last query parameters",
+	DCTL2009: "domain parameter is missing",
+	DCTL2010: "domain parameter is invalid",
+	DCTL2011: "host parameter is required by template hostRequired",
+	DCTL2012: "groupId is not defined in template",
+	DCTL2013: "variable value is missing",
+	DCTL2014: "unexpected variable",
+	DCTL2015: "template has syncPubKeyDomain, but apply url is not signed",
+	DCTL2016: "private key cannot be used for signing",
+	DCTL2017: "private key does not match the published public key",
+	DCTL2018: "not a synchronous apply url",
+	DCTL2019: "apply url providerId does not match template",
+	DCTL2020: "apply url serviceId does not match template",
+	DCTL2021: "duplicate query parameter",
+	DCTL2022: "redirect_uri is not allowed when template does not have syncRedirectDomain",
+	DCTL2023: "redirect_uri host is not in syncRedirectDomain",
+	DCTL2024: "template cannot be applied with the parameters",
+	DCTL2025: "record type cannot be exported to the format",
+	DCTL2026: "cannot look up existing records for conflict handling",
+	DCTL2027: "DNS UPDATE failed",
+	DCTL2028: "record cannot be imported to a template",
+
+	// template test cases
+	DCTL2200: "template does not have a test file",
+	DCTL2201: "test file field validation",
+	DCTL2202: "template cannot be applied with test inputs",
+	DCTL2203: "expected record is missing",
+	DCTL2204: "unexpected record",
+	DCTL2205: "record differs from expected",
+
+	// cross-template conflict messages
+	DCTL3000: "templates set address, CNAME, or redirect records at the same host",
+	DCTL3001: "templates set competing MX records at the same host",
+	DCTL3002: "templates publish separate SPF TXT records at the same host, use SPFM",
+	DCTL3003: "TXT record is deleted by txtConflictMatchingMode of the other template",
+	DCTL3004: "NS delegation conflicts with records of the other template",
+
+	// provider consistency messages
+	DCTL3020: "providerName differs from other templates of the provider",
+	DCTL3021: "syncPubKeyDomain differs from other templates of the provider",
+	DCTL3022: "logoUrl host differs from other templates of the provider",
+
+	// settings and discovery document messages
+	DCTL4000: "settings field validation",
+	DCTL4001: "providerId contains invalid characters",
+	DCTL4002: "settings url must use https",
+	DCTL4003: "width and height should be set when urlSyncUX is defined",
+	DCTL4004: "urlControlPanel should use %domain% variable",
+	DCTL4005: "_domainconnect record is empty",
+	DCTL4006: "_domainconnect record must not have url scheme",
+	DCTL4007: "_domainconnect record has invalid host",
+	DCTL4008: "multiple _domainconnect records",
 
 	// cloudflare messages
 	DCTL5000: "syncBlock is not supported",
@@ -152,6 +381,21 @@ var dctlToString = map[DCTL]string{
 	DCTL5009: "APEXCNAME is not supported",
 	DCTL5010: "zero ttl is not honoured",
 	DCTL5011: "essential is not supported",
+
+	// DNS provider profile file messages
+	DCTL6000: "record type is not supported by DNS provider profile",
+	DCTL6001: "syncBlock is not supported by DNS provider profile",
+	DCTL6002: "syncPubKeyDomain is required by DNS provider profile",
+	DCTL6003: "sharedServiceName is not supported by DNS provider profile",
+	DCTL6004: "syncRedirectDomain is not supported by DNS provider profile",
+	DCTL6005: "multiInstance is not supported by DNS provider profile",
+	DCTL6006: "warnPhishing is not supported by DNS provider profile",
+	DCTL6007: "hostRequired is not supported by DNS provider profile",
+	DCTL6008: "essential is not supported by DNS provider profile",
+	DCTL6009: "conflict matching is not supported by DNS provider profile",
+	DCTL6010: "CNAME or NS at apex is not supported by DNS provider profile",
+	DCTL6011: "ttl is below DNS provider profile minimum",
+	DCTL6012: "ttl is above DNS provider profile maximum",
 }
 
 // dctlLevel maps each DCTL code to its zerolog log level.
@@ -167,6 +411,7 @@ var dctlLevel = map[DCTL]zerolog.Level{
 	DCTL0007: zerolog.ErrorLevel,
 	DCTL0008: zerolog.ErrorLevel,
 	DCTL0009: zerolog.InfoLevel,
+	DCTL0010: zerolog.FatalLevel,
 
 	// domain connect specific messages
 	DCTL1002: zerolog.ErrorLevel,
@@ -208,6 +453,112 @@ var dctlLevel = map[DCTL]zerolog.Level{
 	DCTL1038: zerolog.InfoLevel,
 	DCTL1039: zerolog.InfoLevel,
 	DCTL1040: zerolog.ErrorLevel,
+	DCTL1041: zerolog.ErrorLevel,
+	DCTL1042: zerolog.ErrorLevel,
+	DCTL1043: zerolog.ErrorLevel,
+	DCTL1044: zerolog.ErrorLevel,
+	DCTL1045: zerolog.ErrorLevel,
+	DCTL1046: zerolog.ErrorLevel,
+	DCTL1047: zerolog.ErrorLevel,
+	DCTL1048: zerolog.ErrorLevel,
+	DCTL1049: zerolog.ErrorLevel,
+	DCTL1050: zerolog.ErrorLevel,
+	DCTL1051: zerolog.ErrorLevel,
+	DCTL1052: zerolog.ErrorLevel,
+	DCTL1053: zerolog.ErrorLevel,
+	DCTL1054: zerolog.ErrorLevel,
+	DCTL1055: zerolog.ErrorLevel,
+	DCTL1056: zerolog.ErrorLevel,
+	DCTL1057: zerolog.WarnLevel,
+	DCTL1058: zerolog.ErrorLevel,
+	DCTL1059: zerolog.WarnLevel,
+	DCTL1060: zerolog.ErrorLevel,
+	DCTL1061: zerolog.WarnLevel,
+	DCTL1062: zerolog.ErrorLevel,
+	DCTL1063: zerolog.WarnLevel,
+	DCTL1064: zerolog.WarnLevel,
+	DCTL1065: zerolog.ErrorLevel,
+	DCTL1066: zerolog.ErrorLevel,
+	DCTL1067: zerolog.ErrorLevel,
+	DCTL1068: zerolog.InfoLevel,
+	DCTL1069: zerolog.ErrorLevel,
+	DCTL1070: zerolog.ErrorLevel,
+	DCTL1071: zerolog.ErrorLevel,
+	DCTL1072: zerolog.WarnLevel,
+	DCTL1073: zerolog.ErrorLevel,
+	DCTL1074: zerolog.ErrorLevel,
+	DCTL1075: zerolog.WarnLevel,
+	DCTL1076: zerolog.WarnLevel,
+	DCTL1077: zerolog.ErrorLevel,
+	DCTL1078: zerolog.WarnLevel,
+	DCTL1079: zerolog.WarnLevel,
+	DCTL1080: zerolog.ErrorLevel,
+	DCTL1081: zerolog.WarnLevel,
+	DCTL1082: zerolog.ErrorLevel,
+	DCTL1083: zerolog.WarnLevel,
+	DCTL1084: zerolog.ErrorLevel,
+
+	// apply request and signature messages
+	DCTL2000: zerolog.ErrorLevel,
+	DCTL2001: zerolog.ErrorLevel,
+	DCTL2002: zerolog.ErrorLevel,
+	DCTL2003: zerolog.ErrorLevel,
+	DCTL2004: zerolog.ErrorLevel,
+	DCTL2005: zerolog.ErrorLevel,
+	DCTL2006: zerolog.ErrorLevel,
+	DCTL2007: zerolog.ErrorLevel,
+	DCTL2008: zerolog.WarnLevel,
+	DCTL2009: zerolog.ErrorLevel,
+	DCTL2010: zerolog.ErrorLevel,
+	DCTL2011: zerolog.ErrorLevel,
+	DCTL2012: zerolog.ErrorLevel,
+	DCTL2013: zerolog.ErrorLevel,
+	DCTL2014: zerolog.ErrorLevel,
+	DCTL2015: zerolog.WarnLevel,
+	DCTL2016: zerolog.ErrorLevel,
+	DCTL2017: zerolog.ErrorLevel,
+	DCTL2018: zerolog.ErrorLevel,
+	DCTL2019: zerolog.ErrorLevel,
+	DCTL2020: zerolog.ErrorLevel,
+	DCTL2021: zerolog.WarnLevel,
+	DCTL2022: zerolog.ErrorLevel,
+	DCTL2023: zerolog.ErrorLevel,
+	DCTL2024: zerolog.ErrorLevel,
+	DCTL2025: zerolog.WarnLevel,
+	DCTL2026: zerolog.ErrorLevel,
+	DCTL2027: zerolog.ErrorLevel,
+	DCTL2028: zerolog.WarnLevel,
+
+	// template test cases
+	DCTL2200: zerolog.InfoLevel,
+	DCTL2201: zerolog.ErrorLevel,
+	DCTL2202: zerolog.ErrorLevel,
+	DCTL2203: zerolog.ErrorLevel,
+	DCTL2204: zerolog.ErrorLevel,
+	DCTL2205: zerolog.ErrorLevel,
+
+	// cross-template conflict messages
+	DCTL3000: zerolog.ErrorLevel,
+	DCTL3001: zerolog.WarnLevel,
+	DCTL3002: zerolog.ErrorLevel,
+	DCTL3003: zerolog.WarnLevel,
+	DCTL3004: zerolog.ErrorLevel,
+
+	// provider consistency messages
+	DCTL3020: zerolog.WarnLevel,
+	DCTL3021: zerolog.WarnLevel,
+	DCTL3022: zerolog.InfoLevel,
+
+	// settings and discovery document messages
+	DCTL4000: zerolog.ErrorLevel,
+	DCTL4001: zerolog.ErrorLevel,
+	DCTL4002: zerolog.ErrorLevel,
+	DCTL4003: zerolog.InfoLevel,
+	DCTL4004: zerolog.InfoLevel,
+	DCTL4005: zerolog.ErrorLevel,
+	DCTL4006: zerolog.ErrorLevel,
+	DCTL4007: zerolog.ErrorLevel,
+	DCTL4008: zerolog.ErrorLevel,
 
 	// cloudflare messages
 	DCTL5000: zerolog.ErrorLevel,
@@ -222,6 +573,22 @@ var dctlLevel = map[DCTL]zerolog.Level{
 	DCTL5009: zerolog.ErrorLevel,
 	DCTL5010: zerolog.InfoLevel,
 	DCTL5011: zerolog.InfoLevel,
+
+	// DNS provider profile file messages, levels can be overwritten in
+	// the profile file
+	DCTL6000: zerolog.ErrorLevel,
+	DCTL6001: zerolog.ErrorLevel,
+	DCTL6002: zerolog.ErrorLevel,
+	DCTL6003: zerolog.ErrorLevel,
+	DCTL6004: zerolog.ErrorLevel,
+	DCTL6005: zerolog.ErrorLevel,
+	DCTL6006: zerolog.InfoLevel,
+	DCTL6007: zerolog.ErrorLevel,
+	DCTL6008: zerolog.ErrorLevel,
+	DCTL6009: zerolog.ErrorLevel,
+	DCTL6010: zerolog.ErrorLevel,
+	DCTL6011: zerolog.WarnLevel,
+	DCTL6012: zerolog.WarnLevel,
 }
 
 // Level returns the zerolog.Level associated with this DCTL code.
@@ -236,7 +603,13 @@ func (dctl DCTL) Level() zerolog.Level {
 // Severity returns the exitvals.CheckSeverity bit that corresponds to this
 // DCTL code's log level.
 func (dctl DCTL) Severity() exitvals.CheckSeverity {
-	switch dctl.Level() {
+	return LevelSeverity(dctl.Level())
+}
+
+// LevelSeverity returns the exitvals.CheckSeverity bit that corresponds to
+// a log level.
+func LevelSeverity(level zerolog.Level) exitvals.CheckSeverity {
+	switch level {
 	case zerolog.DebugLevel, zerolog.TraceLevel:
 		return exitvals.CheckDebug
 	case zerolog.InfoLevel:
@@ -250,10 +623,20 @@ func (dctl DCTL) Severity() exitvals.CheckSeverity {
 	}
 }
 
-func (dctl DCTL) MarshalZerologObject(e *zerolog.Event) {
+// String returns the code in DCTLnnnn format.
+func (dctl DCTL) String() string {
+	return fmt.Sprintf("DCTL%04d", uint16(dctl))
+}
+
+// Description returns short explanation of the code.
+func (dctl DCTL) Description() string {
 	description, ok := dctlToString[dctl]
 	if !ok {
 		description = "invalid DCTL code"
 	}
-	e.Str("code", fmt.Sprintf("DCTL%04d", dctl)).Str("dctl_note", description)
+	return description
+}
+
+func (dctl DCTL) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("code", dctl.String()).Str("dctl_note", dctl.Description())
 }
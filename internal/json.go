@@ -98,3 +98,48 @@ func (sint *SINT) Uint16() (uint16, bool) {
 	}
 	return uint16(i), true
 }
+
+// Settings is the DNS provider settings document returned from
+// https://{_domainconnect}/v2/{domain}/settings
+type Settings struct {
+	ProviderID          string   `json:"providerId" validate:"required,min=1,max=64"`
+	ProviderName        string   `json:"providerName" validate:"required,min=1,max=64"`
+	ProviderDisplayName string   `json:"providerDisplayName,omitempty" validate:"omitempty,max=255"`
+	URLSyncUX           string   `json:"urlSyncUX,omitempty" validate:"omitempty,http_url,dcurlhost"`
+	URLAsyncUX          string   `json:"urlAsyncUX,omitempty" validate:"omitempty,http_url,dcurlhost"`
+	URLAPI              string   `json:"urlAPI" validate:"required,http_url,dcurlhost"`
+	Width               uint     `json:"width,omitempty" validate:"omitempty,max=4096"`
+	Height              uint     `json:"height,omitempty" validate:"omitempty,max=4096"`
+	URLControlPanel     string   `json:"urlControlPanel,omitempty"`
+	NameServers         []string `json:"nameServers,omitempty" validate:"omitempty,dive,dcfqdn"`
+}
+
+// ResourceRecord is a DNS record that results from applying a template.
+// Name is relative to the domain, "@" being the domain apex. Data holds
+// the pointsTo, target, or data value of the template record, and the
+// merged SPF TXT record for SPFM records.
+type ResourceRecord struct {
+	Type     string `json:"type" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Data     string `json:"data"`
+	TTL      uint32 `json:"ttl,omitempty"`
+	Priority uint32 `json:"priority,omitempty"`
+	Weight   uint32 `json:"weight,omitempty"`
+	Port     uint32 `json:"port,omitempty"`
+}
+
+// TemplateTests is the content of a template companion test file.
+type TemplateTests struct {
+	Tests []TemplateTest `json:"tests" validate:"required,min=1,dive"`
+}
+
+// TemplateTest is a single test case, the template is applied with the
+// inputs and the result must be exactly the Records.
+type TemplateTest struct {
+	Name      string            `json:"name" validate:"required"`
+	Domain    string            `json:"domain" validate:"required"`
+	Host      string            `json:"host,omitempty"`
+	GroupIDs  []string          `json:"groupId,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Records   []ResourceRecord  `json:"records" validate:"dive"`
+}
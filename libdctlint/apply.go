@@ -0,0 +1,225 @@
+package libdctlint
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// ApplyParams are the inputs of a synchronous apply request.
+type ApplyParams struct {
+	Domain      string
+	Host        string
+	GroupIDs    []string
+	Variables   map[string]string
+	RedirectURI string
+	State       string
+}
+
+// ApplySigning holds the optional signing inputs of BuildApplyURL.
+// Signing is disabled when PrivateKey is empty. PublicKey is optional,
+// when set it is the key published at <Key>.<syncPubKeyDomain> in any
+// format ParsePublicKey() accepts.
+type ApplySigning struct {
+	PrivateKey []byte
+	Key        string
+	PublicKey  []byte
+}
+
+// applyPath returns the synchronous apply url path of a template.
+func applyPath(template internal.Template) string {
+	return "/v2/domainTemplates/providers/" + url.PathEscape(template.ProviderID) +
+		"/services/" + url.PathEscape(template.ServiceID) + "/apply"
+}
+
+// canonicalQuery returns the apply query string in the canonical order:
+// domain, host, variables sorted by name, groupId, redirect_uri, and state.
+func canonicalQuery(params ApplyParams) string {
+	var q []string
+	add := func(name, value string) {
+		q = append(q, url.QueryEscape(name)+"="+url.QueryEscape(value))
+	}
+
+	add("domain", params.Domain)
+	if params.Host != "" {
+		add("host", params.Host)
+	}
+	names := make([]string, 0, len(params.Variables))
+	for name := range params.Variables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		add(name, params.Variables[name])
+	}
+	if 0 < len(params.GroupIDs) {
+		add("groupId", strings.Join(params.GroupIDs, ","))
+	}
+	if params.RedirectURI != "" {
+		add("redirect_uri", params.RedirectURI)
+	}
+	if params.State != "" {
+		add("state", params.State)
+	}
+	return strings.Join(q, "&")
+}
+
+// parsePrivateKey reads a PEM encoded PKCS #1 or PKCS #8 RSA private key.
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not RSA: %T", key)
+		}
+		return rsaKey, nil
+	}
+	return nil, fmt.Errorf("unexpected pem block type '%s'", block.Type)
+}
+
+// checkApplyParams compares apply parameters against the template needs.
+// The variable names in params are case-insensitive.
+func (conf *Conf) checkApplyParams(template internal.Template, params ApplyParams) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+
+	if params.Domain == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2009, nil)
+	} else if err := checkFQDN(params.Domain); err != nil {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2010, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("domain", params.Domain)
+		})
+	}
+
+	if template.HostRequired && params.Host == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2011, nil)
+	}
+
+	groups := make(map[string]bool)
+	for _, record := range template.Records {
+		groups[record.GroupID] = true
+	}
+	for _, groupID := range params.GroupIDs {
+		if !groups[groupID] {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2012, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("groupId", groupID)
+			})
+		}
+	}
+
+	needed := templateVariables(template, params.GroupIDs)
+	supplied := make(map[string]bool)
+	for name := range params.Variables {
+		supplied[strings.ToLower(name)] = true
+	}
+	for _, name := range sortedKeys(needed) {
+		if !supplied[name] {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2013, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("variable", name)
+			})
+		}
+	}
+	for _, name := range sortedKeys(supplied) {
+		if _, ok := needed[name]; !ok {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2014, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("variable", name)
+			})
+		}
+	}
+
+	return exitVal
+}
+
+// sortedKeys returns map keys in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// BuildApplyURL checks the apply parameters against the template, and
+// returns the synchronous apply url in canonical form. The baseURL is the
+// DNS provider urlSyncUX, and may be empty when only the path and query
+// are wanted. The url is signed when signing.PrivateKey is set.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) BuildApplyURL(template internal.Template, baseURL string, params ApplyParams, signing ApplySigning) (string, exitvals.CheckSeverity) {
+	conf.startCheck()
+	exitVal := conf.checkApplyParams(template, params)
+
+	query := canonicalQuery(params)
+	applyURL := strings.TrimSuffix(baseURL, "/") + applyPath(template) + "?" + query
+
+	if len(signing.PrivateKey) == 0 {
+		if template.SyncPubKeyDomain != "" {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2015, nil)
+		}
+		return applyURL, exitVal
+	}
+
+	if template.SyncPubKeyDomain == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2000, nil)
+	}
+	if signing.Key == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2003, nil)
+	}
+
+	privKey, err := parsePrivateKey(signing.PrivateKey)
+	if err != nil {
+		return applyURL, exitVal | conf.emit(conf.tlog, internal.DCTL2016, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	if 0 < len(signing.PublicKey) {
+		pubKey, err := ParsePublicKey(signing.PublicKey)
+		if err != nil {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2005, func(e *zerolog.Event) *zerolog.Event {
+				return e.Err(err)
+			})
+		} else if !pubKey.Equal(&privKey.PublicKey) {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2017, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("record", signing.Key+"."+template.SyncPubKeyDomain)
+			})
+		}
+	}
+
+	digest := sha256.Sum256([]byte(query))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return applyURL, exitVal | conf.emit(conf.tlog, internal.DCTL2016, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+	applyURL += "&sig=" + url.QueryEscape(base64.StdEncoding.EncodeToString(sig)) +
+		"&key=" + url.QueryEscape(signing.Key)
+
+	return applyURL, exitVal
+}
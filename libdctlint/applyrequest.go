@@ -0,0 +1,221 @@
+package libdctlint
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// ApplyRequest is a parsed synchronous apply url. ProviderID and ServiceID
+// are empty when the input did not have the apply path.
+type ApplyRequest struct {
+	ProviderID string
+	ServiceID  string
+	Params     ApplyParams
+	Sig        string
+	Key        string
+	// Other holds parameters that are neither variables nor defined by
+	// the specification, such as providerName and serviceName.
+	Other map[string]string
+
+	query      applyQuery
+	duplicates []string
+}
+
+// reservedParams are apply query parameters that are not template variables.
+var reservedParams = map[string]bool{
+	"domain":       true,
+	"host":         true,
+	"groupId":      true,
+	"redirect_uri": true,
+	"state":        true,
+	"sig":          true,
+	"key":          true,
+	"providerName": true,
+	"serviceName":  true,
+}
+
+var errNotApplyPath = errors.New("path is not /v2/domainTemplates/providers/{providerId}/services/{serviceId}/apply")
+
+// ParseApplyRequest parses a synchronous apply url, or only the query
+// string of it.
+func ParseApplyRequest(applyURL string) (ApplyRequest, error) {
+	req := ApplyRequest{Other: make(map[string]string)}
+
+	path, _, hasQuery := strings.Cut(applyURL, "?")
+	if hasQuery && path != "" {
+		u, err := url.Parse(applyURL)
+		if err != nil {
+			return req, err
+		}
+		elems := strings.Split(strings.Trim(u.Path, "/"), "/")
+		n := len(elems)
+		if n < 6 || elems[n-6] != "domainTemplates" || elems[n-5] != "providers" ||
+			elems[n-3] != "services" || elems[n-1] != "apply" {
+			return req, errNotApplyPath
+		}
+		req.ProviderID = elems[n-4]
+		req.ServiceID = elems[n-2]
+	}
+
+	aq, err := splitApplyQuery(applyURL)
+	if err != nil {
+		return req, err
+	}
+	req.query = aq
+	req.Sig = aq.sig
+	req.Key = aq.key
+
+	values, err := url.ParseQuery(aq.signed)
+	if err != nil {
+		return req, err
+	}
+	req.Params.Variables = make(map[string]string)
+	for name, list := range values {
+		if 1 < len(list) {
+			req.duplicates = append(req.duplicates, name)
+		}
+		value := list[0]
+		switch name {
+		case "domain":
+			req.Params.Domain = value
+		case "host":
+			req.Params.Host = value
+		case "groupId":
+			req.Params.GroupIDs = splitGroupIDs(value)
+		case "redirect_uri":
+			req.Params.RedirectURI = value
+		case "state":
+			req.Params.State = value
+		default:
+			if reservedParams[name] {
+				req.Other[name] = value
+			} else {
+				req.Params.Variables[name] = value
+			}
+		}
+	}
+	slices.Sort(req.duplicates)
+	return req, nil
+}
+
+func splitGroupIDs(s string) []string {
+	var groupIDs []string
+	for groupID := range strings.SplitSeq(s, ",") {
+		if groupID = strings.TrimSpace(groupID); groupID != "" {
+			groupIDs = append(groupIDs, groupID)
+		}
+	}
+	return groupIDs
+}
+
+// redirectAllowed tells if redirect uri host is one of the syncRedirectDomain
+// entries, or a subdomain of an entry.
+func redirectAllowed(redirectURI, srd string) (bool, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return false, err
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return false, errors.New("redirect_uri does not have a host")
+	}
+	for _, domain := range syncRedirectDomains(srd) {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CheckApplyRequest checks a synchronous apply url is valid for the
+// template. When pubKey is not empty the request signature is verified
+// as well, see VerifyApplySignature() for the key formats.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) CheckApplyRequest(template internal.Template, applyURL string, pubKey []byte) exitvals.CheckSeverity {
+	conf.startCheck()
+	exitVal := exitvals.CheckOK
+
+	req, err := ParseApplyRequest(applyURL)
+	if err != nil {
+		if errors.Is(err, errNotApplyPath) {
+			return conf.emit(conf.tlog, internal.DCTL2018, func(e *zerolog.Event) *zerolog.Event {
+				return e.Err(err)
+			})
+		}
+		return conf.emit(conf.tlog, internal.DCTL2001, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	if req.ProviderID == "" {
+		conf.tlog.Debug().Msg("apply url does not have a path, skipping id checks")
+	} else {
+		if req.ProviderID != template.ProviderID {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2019, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("providerId", req.ProviderID).Str("expected", template.ProviderID)
+			})
+		}
+		if req.ServiceID != template.ServiceID {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2020, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("serviceId", req.ServiceID).Str("expected", template.ServiceID)
+			})
+		}
+	}
+
+	for _, name := range req.duplicates {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2021, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("parameter", name)
+		})
+	}
+	if _, ok := req.Other["providerName"]; ok && !template.SharedProviderName {
+		req.Params.Variables["providerName"] = req.Other["providerName"]
+	}
+	if _, ok := req.Other["serviceName"]; ok && !template.SharedServiceName {
+		req.Params.Variables["serviceName"] = req.Other["serviceName"]
+	}
+
+	exitVal |= conf.checkApplyParams(template, req.Params)
+
+	if req.Params.RedirectURI != "" {
+		if template.SyncRedirectDomain == "" {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2022, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("redirect_uri", req.Params.RedirectURI)
+			})
+		} else if ok, err := redirectAllowed(req.Params.RedirectURI, template.SyncRedirectDomain); !ok {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2023, func(e *zerolog.Event) *zerolog.Event {
+				return e.AnErr("error", err).Str("redirect_uri", req.Params.RedirectURI).
+					Str("syncRedirectDomain", template.SyncRedirectDomain)
+			})
+		}
+	}
+
+	if !req.query.sigLast {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2008, nil)
+	}
+	switch {
+	case template.SyncPubKeyDomain == "":
+		if req.Sig != "" {
+			conf.tlog.Debug().Msg("template does not have syncPubKeyDomain, sig is ignored")
+		}
+	case 0 < len(pubKey):
+		exitVal |= conf.verifySignature(template, req.query.signed, req.Sig, req.Key, pubKey)
+	case req.Sig == "":
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2002, nil)
+	case req.Key == "":
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2003, nil)
+	default:
+		conf.tlog.Debug().Msg("public key not given, signature is not verified")
+	}
+
+	return exitVal
+}
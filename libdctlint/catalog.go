@@ -0,0 +1,173 @@
+package libdctlint
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+)
+
+// CatalogEntry describes a template in a repository catalog. ID is the
+// lowercased providerId/serviceId key that template ID collisions are
+// tracked with.
+type CatalogEntry struct {
+	ID             string   `json:"id"`
+	ProviderID     string   `json:"providerId"`
+	ProviderName   string   `json:"providerName"`
+	ServiceID      string   `json:"serviceId"`
+	ServiceName    string   `json:"serviceName"`
+	Version        uint     `json:"version"`
+	Logo           string   `json:"logoUrl,omitempty"`
+	Flags          []string `json:"flags"`
+	RecordTypes    []string `json:"recordTypes"`
+	Variables      []string `json:"variables"`
+	File           string   `json:"file"`
+	Status         string   `json:"status"`
+	DuplicateFiles []string `json:"duplicateFiles,omitempty"`
+}
+
+// Catalog is an index of checked templates. Entries are unique by ID,
+// templates that reuse an ID are listed in DuplicateFiles of the first.
+type Catalog struct {
+	Templates []CatalogEntry `json:"templates"`
+	index     map[string]int
+}
+
+// CatalogDiff lists IDs of services added and removed between catalogs.
+type CatalogDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// NewCatalog creates an empty catalog, see Conf.SetCatalog().
+func NewCatalog() *Catalog {
+	return &Catalog{index: make(map[string]int)}
+}
+
+// ReadCatalog decodes a catalog written earlier.
+func ReadCatalog(r io.Reader) (*Catalog, error) {
+	c := NewCatalog()
+	if err := json.NewDecoder(r).Decode(c); err != nil {
+		return nil, err
+	}
+	// catalogs of older versions can have IDs in mixed case
+	for i, entry := range c.Templates {
+		c.index[strings.ToLower(entry.ID)] = i
+	}
+	return c, nil
+}
+
+// Write encodes the catalog as indented json.
+func (c *Catalog) Write(w io.Writer) error {
+	out := *c
+	if out.Templates == nil {
+		out.Templates = []CatalogEntry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(out)
+}
+
+// Diff returns services that are in c but not in prev, and the other way
+// round, in catalog order.
+func (c *Catalog) Diff(prev *Catalog) CatalogDiff {
+	diff := CatalogDiff{Added: []string{}, Removed: []string{}}
+	for _, entry := range c.Templates {
+		if _, found := prev.index[strings.ToLower(entry.ID)]; !found {
+			diff.Added = append(diff.Added, entry.ID)
+		}
+	}
+	for _, entry := range prev.Templates {
+		if _, found := c.index[strings.ToLower(entry.ID)]; !found {
+			diff.Removed = append(diff.Removed, entry.ID)
+		}
+	}
+	return diff
+}
+
+// collisionKey returns the ID template collisions are tracked with. It is
+// lowercased the same way as TemplateFileName().
+func collisionKey(template internal.Template) string {
+	return strings.ToLower(template.ProviderID + "/" + template.ServiceID)
+}
+
+// severityName returns the most severe level in a check result.
+func severityName(exitVal exitvals.CheckSeverity) string {
+	switch {
+	case exitVal&exitvals.CheckFatal != 0:
+		return "fatal"
+	case exitVal&exitvals.CheckError != 0:
+		return "error"
+	case exitVal&exitvals.CheckWarn != 0:
+		return "warn"
+	case exitVal&exitvals.CheckInfo != 0:
+		return "info"
+	case exitVal&exitvals.CheckDebug != 0:
+		return "debug"
+	}
+	return "ok"
+}
+
+// templateFlags returns names of the boolean template fields that are set.
+func templateFlags(template internal.Template) []string {
+	flags := []string{}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"shared", template.Shared},
+		{"syncBlock", template.SyncBlock},
+		{"sharedProviderName", template.SharedProviderName},
+		{"sharedServiceName", template.SharedServiceName},
+		{"multiInstance", template.MultiInstance},
+		{"warnPhishing", template.WarnPhishing},
+		{"hostRequired", template.HostRequired},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return flags
+}
+
+// addToCatalog adds a checked template to the catalog, when there is one.
+// The first file with an ID owns the entry, the same way DCTL1004 treats
+// the later ones as collisions.
+func (conf *Conf) addToCatalog(template internal.Template, exitVal exitvals.CheckSeverity) {
+	if conf.catalog == nil {
+		return
+	}
+	key := collisionKey(template)
+	if i, found := conf.catalog.index[key]; found && conf.collision[key] != conf.fileName {
+		entry := &conf.catalog.Templates[i]
+		entry.DuplicateFiles = append(entry.DuplicateFiles, conf.fileName)
+		return
+	}
+
+	recordTypes := []string{}
+	for _, record := range template.Records {
+		if !slices.Contains(recordTypes, record.Type) {
+			recordTypes = append(recordTypes, record.Type)
+		}
+	}
+	slices.Sort(recordTypes)
+
+	conf.catalog.index[key] = len(conf.catalog.Templates)
+	conf.catalog.Templates = append(conf.catalog.Templates, CatalogEntry{
+		ID:           key,
+		ProviderID:   template.ProviderID,
+		ProviderName: template.ProviderName,
+		ServiceID:    template.ServiceID,
+		ServiceName:  template.ServiceName,
+		Version:      template.Version,
+		Logo:         template.Logo,
+		Flags:        templateFlags(template),
+		RecordTypes:  recordTypes,
+		Variables:    sortedKeys(templateVariables(template, nil)),
+		File:         conf.fileName,
+		Status:       severityName(exitVal),
+	})
+}
@@ -0,0 +1,27 @@
+package libdctlint
+
+import (
+	"github.com/Domain-Connect/dc-template-linter/internal"
+)
+
+// cloudflareProfile holds Cloudflare specific template rules, the DCTL
+// codes are in range 5000 - 5200.
+var cloudflareProfile = Profile{
+	Name:        "cloudflare",
+	Description: "Cloudflare specific template rules",
+	MinTTL:      1,
+	Codes: map[ProfileRule]internal.DCTL{
+		RuleSyncBlock:           internal.DCTL5000,
+		RuleSyncPubKeyDomain:    internal.DCTL5001,
+		RuleSharedServiceName:   internal.DCTL5002,
+		RuleSyncRedirectDomain:  internal.DCTL5003,
+		RuleMultiInstance:       internal.DCTL5004,
+		RuleWarnPhishing:        internal.DCTL5005,
+		RuleHostRequired:        internal.DCTL5006,
+		RuleApexCNAME:           internal.DCTL5007,
+		RuleTxtConflictMatching: internal.DCTL5008,
+		RuleApexCNAMEType:       internal.DCTL5009,
+		RuleMinTTL:              internal.DCTL5010,
+		RuleEssential:           internal.DCTL5011,
+	},
+}
@@ -0,0 +1,81 @@
+package libdctlint
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// CompatStatus tells how well a DNS provider can apply a template.
+type CompatStatus string
+
+const (
+	// CompatSupported means profile checks did not report anything
+	CompatSupported CompatStatus = "supported"
+	// CompatDegraded means profile checks reported warnings or notes
+	CompatDegraded CompatStatus = "degraded"
+	// CompatUnsupported means profile checks reported errors
+	CompatUnsupported CompatStatus = "unsupported"
+)
+
+// CompatReason is a DNS provider profile finding.
+type CompatReason struct {
+	Code   string `json:"code"`
+	Level  string `json:"level"`
+	Note   string `json:"note"`
+	Record int    `json:"record,omitempty"`
+}
+
+// CompatResult is a template compatibility with a DNS provider profile.
+type CompatResult struct {
+	Profile string         `json:"profile"`
+	Status  CompatStatus   `json:"status"`
+	Reasons []CompatReason `json:"reasons,omitempty"`
+}
+
+// CheckCompatibility runs template checks with every registered DNS
+// provider profile, and returns findings of the profile rules. Other
+// template issues are not part of the results.
+func (conf *Conf) CheckCompatibility(template internal.Template) []CompatResult {
+	var results []CompatResult
+
+	for _, name := range ProfileNames() {
+		profile := profiles[name]
+		pconf := NewConf().SetLib(true).SetProfile(profile).SetFilename(conf.fileName)
+		pconf.startCheck()
+		pconf.checkTemplate(template)
+
+		profileCodes := make([]internal.DCTL, 0, len(profile.Codes))
+		for _, code := range profile.Codes {
+			profileCodes = append(profileCodes, code)
+		}
+
+		result := CompatResult{Profile: name, Status: CompatSupported}
+		for _, msg := range pconf.GetMessages() {
+			if !slices.Contains(profileCodes, msg.Code) {
+				continue
+			}
+			var fields struct {
+				Record int `json:"record"`
+			}
+			_ = json.Unmarshal([]byte(msg.Message), &fields)
+			result.Reasons = append(result.Reasons, CompatReason{
+				Code:   msg.Code.String(),
+				Level:  msg.Level.String(),
+				Note:   msg.Code.Description(),
+				Record: fields.Record,
+			})
+			if zerolog.ErrorLevel <= msg.Level {
+				result.Status = CompatUnsupported
+			} else if result.Status == CompatSupported {
+				result.Status = CompatDegraded
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
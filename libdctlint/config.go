@@ -18,28 +18,33 @@ type DCTLMessage struct {
 // be updated each time CheckTemplate() is called to match with the
 // bufio.Reader argument.
 type Conf struct {
-	fileName    string
-	tlog        zerolog.Logger
-	toleration  zerolog.Level
-	collision   map[string]bool
-	duplicates  map[uint64]bool
-	checkLogos  bool
-	mergeOrFail bool
-	cloudflare  bool
-	inplace     bool
-	increment   bool
-	prettyPrint bool
-	ttl         uint32
-	indent      uint
-	lib         bool
-	messages    []DCTLMessage
-	sharedvar   string
+	fileName      string
+	tlog          zerolog.Logger
+	toleration    zerolog.Level
+	collision     map[string]string
+	catalog       *Catalog
+	providers     map[string][]providerTemplate
+	providerOrder []string
+	duplicates    map[uint64]bool
+	checkLogos    bool
+	mergeOrFail   bool
+	profile       *Profile
+	inplace       bool
+	increment     bool
+	prettyPrint   bool
+	ttl           uint32
+	indent        uint
+	lib           bool
+	messages      []DCTLMessage
+	sharedvar     string
+	varLength     uint
 }
 
 // NewConf will create template check configuration.
 func NewConf() *Conf {
 	return &Conf{
-		collision: make(map[string]bool),
+		collision: make(map[string]string),
+		varLength: DefaultVariableLength,
 	}
 }
 
@@ -48,6 +53,10 @@ func (c *Conf) SetFilename(fn string) *Conf {
 	return c
 }
 
+func (c *Conf) GetFilename() string {
+	return c.fileName
+}
+
 func (c *Conf) SetLogger(l zerolog.Logger) *Conf {
 	c.tlog = l
 	return c
@@ -85,8 +94,21 @@ func (c *Conf) SetMergeOrFail(b bool) *Conf {
 	return c
 }
 
+// SetCloudflare is a shorthand to SetProfile() with the cloudflare
+// profile. False disables DNS provider profile checks.
 func (c *Conf) SetCloudflare(b bool) *Conf {
-	c.cloudflare = b
+	if b {
+		c.profile = &cloudflareProfile
+	} else {
+		c.profile = nil
+	}
+	return c
+}
+
+// SetProfile selects DNS provider profile that is used in template
+// checks, see LookupProfile(). Nil disables DNS provider profile checks.
+func (c *Conf) SetProfile(p *Profile) *Conf {
+	c.profile = p
 	return c
 }
 
@@ -110,6 +132,20 @@ func (c *Conf) SetPrettyPrint(b bool) *Conf {
 	return c
 }
 
+// SetVariableLength sets the worst case length of a variable value, the
+// host parameter, and the domain when checking names fit DNS limits.
+func (c *Conf) SetVariableLength(l uint) *Conf {
+	c.varLength = l
+	return c
+}
+
+// SetCatalog sets the catalog checked templates are added to. A nil
+// catalog disables cataloging.
+func (c *Conf) SetCatalog(catalog *Catalog) *Conf {
+	c.catalog = catalog
+	return c
+}
+
 func (c *Conf) SetTTL(t uint32) *Conf {
 	c.ttl = t
 	return c
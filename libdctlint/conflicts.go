@@ -0,0 +1,141 @@
+package libdctlint
+
+import (
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// TemplateConflict is a record pair that conflicts when two templates are
+// applied to the same domain.
+type TemplateConflict struct {
+	Code        string `json:"code"`
+	Level       string `json:"level"`
+	Note        string `json:"note"`
+	Host        string `json:"host"`
+	Record      int    `json:"record"`
+	OtherRecord int    `json:"otherRecord"`
+}
+
+// ConflictResult lists conflicts between two templates, identified by
+// providerId/serviceId, and by their index in the CheckConflicts() input.
+type ConflictResult struct {
+	Template   string             `json:"template"`
+	Other      string             `json:"other"`
+	Index      int                `json:"index"`
+	OtherIndex int                `json:"otherIndex"`
+	Conflicts  []TemplateConflict `json:"conflicts"`
+}
+
+var addressTypes = map[string]bool{
+	"A":        true,
+	"AAAA":     true,
+	strCNAME:   true,
+	"REDIR301": true,
+	"REDIR302": true,
+}
+
+func isSPF(rr internal.ResourceRecord) bool {
+	return rr.Type == "TXT" && strings.HasPrefix(strings.ToLower(rr.Data), "v=spf1")
+}
+
+// belowName tells if name is the same as or below parent.
+func belowName(name, parent string) bool {
+	return parent == "@" || strings.EqualFold(name, parent) ||
+		strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(parent))
+}
+
+// recordConflict returns the DCTL code of a conflict between record a of
+// one template and record b of another, or zero when they can coexist.
+func recordConflict(a, b internal.ResourceRecord, recA, recB internal.Record) internal.DCTL {
+	if a.Type == "NS" && belowName(b.Name, a.Name) || b.Type == "NS" && belowName(a.Name, b.Name) {
+		if a.Type != b.Type || a.Data != b.Data {
+			return internal.DCTL3004
+		}
+		return 0
+	}
+	if !strings.EqualFold(a.Name, b.Name) {
+		return 0
+	}
+	sameData := a.Type == b.Type && a.Data == b.Data
+	switch {
+	case (a.Type == strCNAME || b.Type == strCNAME) && !sameData:
+		return internal.DCTL3000
+	case addressTypes[a.Type] && addressTypes[b.Type] && !sameData:
+		return internal.DCTL3000
+	case a.Type == "MX" && b.Type == "MX" && !sameData:
+		return internal.DCTL3001
+	case isSPF(a) && isSPF(b) && !sameData:
+		return internal.DCTL3002
+	case a.Type == "TXT" && b.Type == "TXT" && !sameData &&
+		(instanceConflict(a, b, recB) || instanceConflict(b, a, recA)):
+		return internal.DCTL3003
+	}
+	return 0
+}
+
+// CheckConflicts reports templates that conflict when they are applied to
+// the same domain. Every template pair is compared with the records
+// rendered the way a DNS provider would apply them, and variables get
+// template specific values. Templates that require host are skipped,
+// because they are applied to a host of the user's choice.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) CheckConflicts(templates []internal.Template) ([]ConflictResult, exitvals.CheckSeverity) {
+	conf.messages = nil
+	logger := conf.baseLogger()
+	exitVal := exitvals.CheckOK
+
+	rendered := make([][]internal.ResourceRecord, len(templates))
+	for i, template := range templates {
+		if template.HostRequired {
+			logger.Debug().Str("template", templateID(template)).Msg("hostRequired template, skipping conflict checks")
+			continue
+		}
+		rendered[i] = instanceRecords(template, i+1)
+	}
+
+	var results []ConflictResult
+	for i := range templates {
+		for j := i + 1; j < len(templates); j++ {
+			result := ConflictResult{
+				Template:   templateID(templates[i]),
+				Other:      templateID(templates[j]),
+				Index:      i,
+				OtherIndex: j,
+			}
+			plog := logger.With().Str("template", result.Template).Str("other", result.Other).Logger()
+			for ra, a := range rendered[i] {
+				for rb, b := range rendered[j] {
+					dctl := recordConflict(a, b, templates[i].Records[ra], templates[j].Records[rb])
+					if dctl == 0 {
+						continue
+					}
+					exitVal |= conf.emit(plog, dctl, func(e *zerolog.Event) *zerolog.Event {
+						return e.Str("host", a.Name).Int("record", ra+1).Int("otherrecord", rb+1)
+					})
+					result.Conflicts = append(result.Conflicts, TemplateConflict{
+						Code:        dctl.String(),
+						Level:       dctl.Level().String(),
+						Note:        dctl.Description(),
+						Host:        a.Name,
+						Record:      ra + 1,
+						OtherRecord: rb + 1,
+					})
+				}
+			}
+			if 0 < len(result.Conflicts) {
+				results = append(results, result)
+			}
+		}
+	}
+	return results, exitVal
+}
+
+func templateID(template internal.Template) string {
+	return template.ProviderID + "/" + template.ServiceID
+}
@@ -0,0 +1,103 @@
+package libdctlint
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+const minDKIMKeyBits = 2048
+
+// parseDKIMKey returns the key type, as used in k= tag, and size in bits of
+// a DKIM public key. RFC 8463 ed25519 keys are the raw 32 byte key, RSA keys
+// are SubjectPublicKeyInfo or PKCS #1 encoded.
+func parseDKIMKey(der []byte) (string, int, error) {
+	if len(der) == ed25519.PublicKeySize {
+		return "ed25519", ed25519.PublicKeySize * 8, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			return "rsa", k.N.BitLen(), nil
+		case ed25519.PublicKey:
+			return "ed25519", 0, errors.New("ed25519 key must be the raw 32 byte key, not SubjectPublicKeyInfo")
+		default:
+			return "", 0, fmt.Errorf("unsupported key type %T", key)
+		}
+	}
+	key, err := x509.ParsePKCS1PublicKey(der)
+	if err != nil {
+		return "", 0, errors.New("not a PKIX or PKCS #1 public key")
+	}
+	return "rsa", key.N.BitLen(), nil
+}
+
+// checkDKIMKey inspects the public key of a DKIM key record.
+func (conf *Conf) checkDKIMKey(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	if txtFormatFor(record.Host) != txtFormats["dkim"] || checkBareVariables(record.Data) {
+		return exitvals.CheckOK
+	}
+	tags, _ := parseTagList(record.Data)
+	keyType := "rsa"
+	keyTypeVariable := false
+	var p *txtTag
+	for i := range tags {
+		switch tags[i].name {
+		case "k":
+			keyType = strings.ToLower(tags[i].value)
+			keyTypeVariable = isVariable(keyType)
+		case "p":
+			p = &tags[i]
+		}
+	}
+	switch {
+	case p == nil:
+		return exitvals.CheckOK
+	case isVariable(p.value):
+		rlog.Debug().Str("p", p.value).Msg("dkim key is a variable, skipping key checks")
+		return exitvals.CheckOK
+	case p.value == "":
+		rlog.Debug().Msg("dkim key is revoked")
+		return exitvals.CheckOK
+	}
+
+	exitVal := exitvals.CheckOK
+	if strings.ContainsAny(p.value, " \t\"") {
+		exitVal |= conf.emit(rlog, internal.DCTL1061, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("p", p.value)
+		})
+	}
+	der, err := base64.StdEncoding.DecodeString(base64Data(p.value))
+	if err != nil {
+		// reported by checkTXTContent()
+		return exitVal
+	}
+	realType, bits, err := parseDKIMKey(der)
+	if err != nil {
+		return exitVal | conf.emit(rlog, internal.DCTL1058, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+	rlog.Debug().Str("keytype", realType).Int("keybits", bits).Msg("dkim key")
+
+	if !keyTypeVariable && keyType != realType {
+		exitVal |= conf.emit(rlog, internal.DCTL1060, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("k", keyType).Str("keytype", realType)
+		})
+	}
+	if realType == "rsa" && bits < minDKIMKeyBits {
+		exitVal |= conf.emit(rlog, internal.DCTL1059, func(e *zerolog.Event) *zerolog.Event {
+			return e.Int("keybits", bits).Int("min", minDKIMKeyBits)
+		})
+	}
+	return exitVal
+}
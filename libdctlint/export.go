@@ -0,0 +1,466 @@
+package libdctlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is an infrastructure as code format rendered templates can
+// be exported to.
+type ExportFormat string
+
+const (
+	// ExportTerraform is hashicorp/dns provider HCL resources
+	ExportTerraform ExportFormat = "terraform"
+	// ExportOctoDNS is an octoDNS YAML zone fragment
+	ExportOctoDNS ExportFormat = "octodns"
+	// ExportDNSControl is a DNSControl D() JavaScript snippet
+	ExportDNSControl ExportFormat = "dnscontrol"
+)
+
+// exporter writes record sets in an export format, and returns records
+// the format cannot express.
+type exporter func(w *bytes.Buffer, domain string, sets []rrSet) []internal.ResourceRecord
+
+var exporters = map[ExportFormat]exporter{
+	ExportTerraform:  exportTerraform,
+	ExportOctoDNS:    exportOctoDNS,
+	ExportDNSControl: exportDNSControl,
+}
+
+// ExportFormats returns supported export format names in sorted order.
+func ExportFormats() []string {
+	names := make([]string, 0, len(exporters))
+	for format := range exporters {
+		names = append(names, string(format))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// rrSet is records of the same name and type. The ttl is taken from the
+// first record.
+type rrSet struct {
+	name    string
+	rtype   string
+	ttl     uint32
+	records []internal.ResourceRecord
+}
+
+// groupRRSets groups records to sets in the order the sets first appear.
+func groupRRSets(rrs []internal.ResourceRecord) []rrSet {
+	var sets []rrSet
+	index := make(map[string]int)
+	for _, rr := range rrs {
+		key := strings.ToLower(rr.Name) + "/" + rr.Type
+		i, ok := index[key]
+		if !ok {
+			i = len(sets)
+			index[key] = i
+			sets = append(sets, rrSet{name: rr.Name, rtype: rr.Type, ttl: rr.TTL})
+		}
+		sets[i].records = append(sets[i].records, rr)
+	}
+	return sets
+}
+
+// fqdnTarget returns a target name with trailing dot.
+func fqdnTarget(s string) string {
+	if strings.HasSuffix(s, ".") {
+		return s
+	}
+	return s + "."
+}
+
+// rdataFields splits presentation format record data, numeric fields are
+// converted to numbers.
+func rdataFields(data string) []any {
+	fields, err := splitRData(data)
+	if err != nil {
+		return []any{data}
+	}
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		if n, err := strconv.Atoi(field); err == nil {
+			values[i] = n
+		} else {
+			values[i] = field
+		}
+	}
+	return values
+}
+
+// ExportTemplate applies the template with params, and writes the records
+// in an infrastructure as code format. Records the format cannot express
+// are reported and left out.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) ExportTemplate(w io.Writer, template internal.Template, params ApplyParams, format ExportFormat) exitvals.CheckSeverity {
+	conf.startCheck()
+	export, ok := exporters[format]
+	if !ok {
+		conf.tlog.Error().Str("format", string(format)).Strs("known", ExportFormats()).Msg("unknown export format")
+		return exitvals.CheckFatal
+	}
+	exitVal := conf.checkApplyParams(template, params)
+
+	rrs, err := RenderTemplate(template, params)
+	if err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2024, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	var out bytes.Buffer
+	skipped := export(&out, strings.TrimSuffix(params.Domain, "."), groupRRSets(rrs))
+	for _, rr := range skipped {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2025, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("format", string(format)).Str("type", rr.Type).Str("name", rr.Name)
+		})
+	}
+	if _, err := out.WriteTo(w); err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL0004, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+	return exitVal
+}
+
+// hclString quotes a string for HCL, template sequences are escaped.
+func hclString(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q, "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+func hclList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = hclString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// hclAttrs writes aligned attribute lines.
+func hclAttrs(w *bytes.Buffer, indent string, attrs [][2]string) {
+	width := 0
+	for _, attr := range attrs {
+		width = max(width, len(attr[0]))
+	}
+	for _, attr := range attrs {
+		fmt.Fprintf(w, "%s%-*s = %s\n", indent, width, attr[0], attr[1])
+	}
+}
+
+var hclIdentRe = regexp.MustCompile(`[^0-9A-Za-z_-]`)
+
+// terraformNames makes unique resource names out of record names.
+type terraformNames map[string]bool
+
+func (used terraformNames) name(rtype, name string) string {
+	if name == "@" {
+		name = "apex"
+	}
+	name = hclIdentRe.ReplaceAllString(name, "_")
+	if name[0] < 'A' && name[0] != '_' {
+		name = "r_" + name
+	}
+	base := strings.ToLower(rtype) + "_" + name
+	unique := base
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+func exportTerraform(w *bytes.Buffer, domain string, sets []rrSet) []internal.ResourceRecord {
+	var skipped []internal.ResourceRecord
+	used := make(terraformNames)
+	zone := hclString(fqdnTarget(domain))
+
+	resource := func(resourceType string, set rrSet, attrs [][2]string, blocks []string) {
+		fmt.Fprintf(w, "resource %q %q {\n", resourceType, used.name(set.rtype, set.name))
+		head := [][2]string{{"zone", zone}}
+		if set.name != "@" {
+			head = append(head, [2]string{"name", hclString(set.name)})
+		}
+		head = append(head, attrs...)
+		head = append(head, [2]string{"ttl", strconv.FormatUint(uint64(set.ttl), 10)})
+		hclAttrs(w, "  ", head)
+		for _, block := range blocks {
+			w.WriteString("\n" + block)
+		}
+		w.WriteString("}\n\n")
+	}
+
+	for _, set := range sets {
+		var values []string
+		for _, rr := range set.records {
+			values = append(values, rr.Data)
+		}
+		switch set.rtype {
+		case "A":
+			resource("dns_a_record_set", set, [][2]string{{"addresses", hclList(values)}}, nil)
+		case "AAAA":
+			resource("dns_aaaa_record_set", set, [][2]string{{"addresses", hclList(values)}}, nil)
+		case "TXT":
+			resource("dns_txt_record_set", set, [][2]string{{"txt", hclList(values)}}, nil)
+		case "NS":
+			for i := range values {
+				values[i] = fqdnTarget(values[i])
+			}
+			resource("dns_ns_record_set", set, [][2]string{{"nameservers", hclList(values)}}, nil)
+		case strCNAME:
+			for _, rr := range set.records {
+				one := set
+				one.records = []internal.ResourceRecord{rr}
+				resource("dns_cname_record", one, [][2]string{{"cname", hclString(fqdnTarget(rr.Data))}}, nil)
+			}
+		case "MX", "SRV":
+			var blocks []string
+			for _, rr := range set.records {
+				var block bytes.Buffer
+				var attrs [][2]string
+				if rr.Type == "MX" {
+					block.WriteString("  mx {\n")
+					attrs = [][2]string{
+						{"preference", strconv.FormatUint(uint64(rr.Priority), 10)},
+						{"exchange", hclString(fqdnTarget(rr.Data))},
+					}
+				} else {
+					block.WriteString("  srv {\n")
+					attrs = [][2]string{
+						{"priority", strconv.FormatUint(uint64(rr.Priority), 10)},
+						{"weight", strconv.FormatUint(uint64(rr.Weight), 10)},
+						{"port", strconv.FormatUint(uint64(rr.Port), 10)},
+						{"target", hclString(fqdnTarget(rr.Data))},
+					}
+				}
+				hclAttrs(&block, "    ", attrs)
+				block.WriteString("  }\n")
+				blocks = append(blocks, block.String())
+			}
+			resource("dns_"+strings.ToLower(set.rtype)+"_record_set", set, nil, blocks)
+		default:
+			skipped = append(skipped, set.records...)
+		}
+	}
+	if bytes.HasSuffix(w.Bytes(), []byte("\n\n")) {
+		w.Truncate(w.Len() - 1)
+	}
+	return skipped
+}
+
+// octoDNSValue returns an octoDNS record value, or nil when the type is
+// not supported.
+func octoDNSValue(rr internal.ResourceRecord) any {
+	fields := rdataFields(rr.Data)
+	named := func(names ...string) any {
+		if len(fields) != len(names) {
+			return nil
+		}
+		value := make(map[string]any, len(names))
+		for i, name := range names {
+			value[name] = fields[i]
+		}
+		return value
+	}
+
+	switch rr.Type {
+	case "A", "AAAA":
+		return rr.Data
+	case "NS", strCNAME, "APEXCNAME":
+		return fqdnTarget(rr.Data)
+	case "TXT":
+		return strings.ReplaceAll(rr.Data, ";", "\\;")
+	case "MX":
+		return map[string]any{"preference": rr.Priority, "exchange": fqdnTarget(rr.Data)}
+	case "SRV":
+		return map[string]any{"priority": rr.Priority, "weight": rr.Weight, "port": rr.Port, "target": fqdnTarget(rr.Data)}
+	case "CAA":
+		return named("flags", "tag", "value")
+	case "TLSA":
+		return named("certificate_usage", "selector", "matching_type", "certificate_association_data")
+	case "SSHFP":
+		return named("algorithm", "fingerprint_type", "fingerprint")
+	case "DS":
+		return named("key_tag", "algorithm", "digest_type", "digest")
+	case "REDIR301", "REDIR302":
+		code := 301
+		if rr.Type == "REDIR302" {
+			code = 302
+		}
+		return map[string]any{"code": code, "masking": 2, "path": "/", "query": 0, "target": rr.Data}
+	}
+	return nil
+}
+
+var octoDNSTypes = map[string]string{
+	"APEXCNAME": "ALIAS",
+	"REDIR301":  "URLFWD",
+	"REDIR302":  "URLFWD",
+}
+
+func exportOctoDNS(w *bytes.Buffer, _ string, sets []rrSet) []internal.ResourceRecord {
+	var skipped []internal.ResourceRecord
+	zone := make(map[string][]map[string]any)
+
+	for _, set := range sets {
+		var values []any
+		for _, rr := range set.records {
+			value := octoDNSValue(rr)
+			if value == nil {
+				skipped = append(skipped, rr)
+				continue
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		rtype := set.rtype
+		if t, ok := octoDNSTypes[rtype]; ok {
+			rtype = t
+		}
+		record := map[string]any{"type": rtype, "ttl": set.ttl}
+		switch rtype {
+		case strCNAME, "ALIAS":
+			record["value"] = values[0]
+		default:
+			record["values"] = values
+		}
+		name := strings.ToLower(set.name)
+		if name == "@" {
+			name = ""
+		}
+		if name == "" && rtype == strCNAME || name != "" && rtype == "ALIAS" {
+			skipped = append(skipped, set.records...)
+			continue
+		}
+		zone[name] = append(zone[name], record)
+	}
+
+	// a name with a single record is written as a map
+	doc := make(map[string]any, len(zone))
+	for name, records := range zone {
+		if len(records) == 1 {
+			doc[name] = records[0]
+		} else {
+			doc[name] = records
+		}
+	}
+	w.WriteString("---\n")
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	_ = encoder.Encode(doc)
+	_ = encoder.Close()
+	return skipped
+}
+
+// jsString quotes a string for JavaScript.
+func jsString(s string) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// dnsControlArgs returns DNSControl record function arguments after name,
+// or nil when the type is not supported.
+func dnsControlArgs(rr internal.ResourceRecord) (string, []string) {
+	num := func(n uint32) string {
+		return strconv.FormatUint(uint64(n), 10)
+	}
+	fields, _ := splitRData(rr.Data)
+	data := func(quoted ...bool) []string {
+		if len(fields) < len(quoted) {
+			return nil
+		}
+		args := make([]string, len(quoted))
+		for i, q := range quoted {
+			if q {
+				args[i] = jsString(fields[i])
+			} else {
+				args[i] = fields[i]
+			}
+		}
+		return args
+	}
+
+	switch rr.Type {
+	case "A", "AAAA", "TXT":
+		return rr.Type, []string{jsString(rr.Data)}
+	case strCNAME, "NS":
+		return rr.Type, []string{jsString(fqdnTarget(rr.Data))}
+	case "APEXCNAME":
+		return "ALIAS", []string{jsString(fqdnTarget(rr.Data))}
+	case "MX":
+		return "MX", []string{num(rr.Priority), jsString(fqdnTarget(rr.Data))}
+	case "SRV":
+		return "SRV", []string{num(rr.Priority), num(rr.Weight), num(rr.Port), jsString(fqdnTarget(rr.Data))}
+	case "REDIR301":
+		return "URL301", []string{jsString(rr.Data)}
+	case "REDIR302":
+		return "URL", []string{jsString(rr.Data)}
+	case "CAA":
+		args := data(false, true, true)
+		if args == nil {
+			return "", nil
+		}
+		if args[0] == "128" {
+			return "CAA", []string{args[1], args[2], "CAA_CRITICAL"}
+		}
+		return "CAA", args[1:]
+	case "TLSA":
+		return "TLSA", data(false, false, false, true)
+	case "SSHFP":
+		return "SSHFP", data(false, false, true)
+	case "DS":
+		return "DS", data(false, false, false, true)
+	case "SVCB", "HTTPS":
+		if len(fields) < 2 {
+			return "", nil
+		}
+		return rr.Type, []string{fields[0], jsString(fields[1]), jsString(strings.Join(fields[2:], " "))}
+	}
+	return "", nil
+}
+
+func exportDNSControl(w *bytes.Buffer, domain string, sets []rrSet) []internal.ResourceRecord {
+	var skipped []internal.ResourceRecord
+	var lines []string
+	for _, set := range sets {
+		for _, rr := range set.records {
+			function, args := dnsControlArgs(rr)
+			if args == nil {
+				skipped = append(skipped, rr)
+				continue
+			}
+			args = append([]string{jsString(rr.Name)}, args...)
+			args = append(args, fmt.Sprintf("TTL(%d)", rr.TTL))
+			lines = append(lines, fmt.Sprintf("\t%s(%s)", function, strings.Join(args, ", ")))
+		}
+	}
+	fmt.Fprintf(w, "D(%s, REG_NONE, DnsProvider(DSP_NONE),\n", jsString(domain))
+	w.WriteString(strings.Join(lines, ",\n"))
+	if 0 < len(lines) {
+		w.WriteString("\n")
+	}
+	w.WriteString(");\n")
+	return skipped
+}
@@ -0,0 +1,150 @@
+package libdctlint
+
+import (
+	"bytes"
+	"encoding/gob"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/rs/zerolog"
+)
+
+// groupCombination returns the sorted groupIds that must be applied for
+// both records to be present. Records without groupId are applied with
+// every group selection.
+func groupCombination(a, b string) []string {
+	var groups []string
+	for _, g := range []string{a, b} {
+		if g != "" && !slices.Contains(groups, g) {
+			groups = append(groups, g)
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
+
+// contentKey is a checksum of record content without group specific
+// fields, the same record in two groups has the same key. An empty host
+// is the same as @.
+func contentKey(record internal.Record) (uint64, error) {
+	record.GroupID = ""
+	record.Essential = ""
+	if record.Host == "" {
+		record.Host = "@"
+	}
+	var bybuf bytes.Buffer
+	if err := gob.NewEncoder(&bybuf).Encode(record); err != nil {
+		return 0, err
+	}
+	return xxhash.Sum64(bybuf.Bytes()), nil
+}
+
+func essentialValue(record internal.Record) string {
+	if record.Essential == "" {
+		return "Always"
+	}
+	return record.Essential
+}
+
+// checkGroups analyses what happens when apply requests select a subset of
+// groups. Conflicts and duplicates involve two records, so checking each
+// record pair with the smallest group combination that applies both of them
+// covers every combination. Findings within a single group are reported by
+// checkRecord().
+func (conf *Conf) checkGroups(template internal.Template) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	groups := make(map[string][]int)
+	for rnum, record := range template.Records {
+		groups[record.GroupID] = append(groups[record.GroupID], rnum)
+	}
+	if len(groups) < 2 {
+		return exitVal
+	}
+
+	recordLog := func(rnum int) zerolog.Logger {
+		record := template.Records[rnum]
+		return conf.tlog.With().Str("groupid", record.GroupID).Int("record", rnum+1).Str("type", record.Type).Logger()
+	}
+	keys := make([]uint64, len(template.Records))
+	for rnum, record := range template.Records {
+		key, err := contentKey(record)
+		if err != nil {
+			conf.tlog.Error().Err(err).Msg("could not encode record when finding duplicates")
+			return exitVal | exitvals.CheckError
+		}
+		keys[rnum] = key
+	}
+
+	aloneConflict := make(map[string]bool)
+	for j, b := range template.Records {
+		for i, a := range template.Records[:j] {
+			if a.GroupID == b.GroupID {
+				continue
+			}
+			combination := groupCombination(a.GroupID, b.GroupID)
+			sameOwner := strings.EqualFold(ownerName(&a), ownerName(&b))
+
+			if sameOwner && (a.Type == strCNAME || b.Type == strCNAME) {
+				exitVal |= conf.emit(recordLog(j), internal.DCTL1077, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("host", b.Host).Str("othertype", a.Type).Int("otherrecord", i+1).Strs("groups", combination)
+				})
+				if len(combination) == 1 {
+					aloneConflict[combination[0]] = true
+				}
+			}
+			if keys[i] == keys[j] {
+				exitVal |= conf.emit(recordLog(j), internal.DCTL1078, func(e *zerolog.Event) *zerolog.Event {
+					return e.Int("otherrecord", i+1).Strs("groups", combination)
+				})
+			}
+			if sameOwner && a.Type == b.Type && essentialValue(a) != essentialValue(b) {
+				exitVal |= conf.emit(recordLog(j), internal.DCTL1081, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("essential", essentialValue(b)).Int("otherrecord", i+1).
+						Str("otheressential", essentialValue(a)).Strs("groups", combination)
+				})
+			}
+		}
+	}
+
+	hostRecord := func(rnums []int) bool {
+		return slices.ContainsFunc(rnums, func(rnum int) bool {
+			record := template.Records[rnum]
+			return (record.Type == "NS" || record.Type == strCNAME) && (record.Host == "@" || record.Host == "")
+		})
+	}
+	// DCTL1037 covers templates where no group has the host record
+	all := make([]int, len(template.Records))
+	for rnum := range all {
+		all[rnum] = rnum
+	}
+	hostRequired := template.HostRequired && hostRecord(all)
+
+	for _, groupID := range sortedKeys(groups) {
+		if groupID == "" {
+			continue
+		}
+		rnums := groups[groupID]
+		glog := conf.tlog.With().Str("groupid", groupID).Logger()
+
+		if hostRequired && !hostRecord(rnums) && !hostRecord(groups[""]) {
+			exitVal |= conf.emit(glog, internal.DCTL1080, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("reason", "hostRequired is not satisfied")
+			})
+		}
+		if aloneConflict[groupID] {
+			exitVal |= conf.emit(glog, internal.DCTL1080, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("reason", "CNAME conflict with records without groupId")
+			})
+		}
+		if !slices.ContainsFunc(rnums, func(rnum int) bool {
+			return essentialValue(template.Records[rnum]) != "OnApply"
+		}) {
+			exitVal |= conf.emit(glog, internal.DCTL1079, nil)
+		}
+	}
+	return exitVal
+}
@@ -0,0 +1,104 @@
+package libdctlint
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+var errIPTarget = errors.New("target is an IP address")
+
+// checkHostname validates a target name using LDH rules. Variables are
+// skipped and the literal text around them is checked segment by segment.
+// The underscore is accepted when allowUnderscore is true, CNAME targets
+// often point to names such as selector._domainkey.example.net.
+func checkHostname(name string, allowUnderscore bool) error {
+	if name == "@" {
+		return nil
+	}
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return errors.New("empty name")
+	}
+	if !isVariable(name) && net.ParseIP(name) != nil {
+		return errIPTarget
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if label == "" {
+			return errors.New("empty label")
+		}
+		for _, segment := range variableRe.Split(label, -1) {
+			for _, c := range segment {
+				switch {
+				case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-':
+				case c == '_' && allowUnderscore:
+				default:
+					return fmt.Errorf("invalid character '%c' in label '%s'", c, label)
+				}
+			}
+		}
+		if strings.Contains(label, "%") {
+			continue
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("label '%s' starts or ends with hyphen", label)
+		}
+		if i == len(labels)-1 && 1 < len(labels) && strings.Trim(label, "0123456789") == "" {
+			return fmt.Errorf("top level label '%s' is numeric", label)
+		}
+	}
+	return nil
+}
+
+// checkTargetName validates CNAME, NS, MX, and SRV target names. A lone
+// dot is RFC 7505 null MX, or SRV record telling the service is not
+// available.
+func (conf *Conf) checkTargetName(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	field, target := "pointsTo", record.PointsTo
+	if record.Type == "SRV" {
+		field, target = "target", record.Target
+	}
+	if target == "" || checkBareVariables(target) {
+		return exitvals.CheckOK
+	}
+
+	if target == "." {
+		switch record.Type {
+		case "MX":
+			exitVal := exitvals.CheckOK
+			if priority, ok := record.Priority.Uint32(); ok && priority != 0 {
+				exitVal |= conf.emit(rlog, internal.DCTL1067, func(e *zerolog.Event) *zerolog.Event {
+					return e.Uint32("priority", priority)
+				})
+			}
+			return exitVal | conf.emit(rlog, internal.DCTL1068, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str(field, target)
+			})
+		case "SRV":
+			return conf.emit(rlog, internal.DCTL1068, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str(field, target)
+			})
+		}
+	}
+
+	err := checkHostname(target, record.Type == strCNAME)
+	switch {
+	case err == nil:
+		return exitvals.CheckOK
+	case errors.Is(err, errIPTarget):
+		return conf.emit(rlog, internal.DCTL1066, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str(field, target)
+		})
+	}
+	return conf.emit(rlog, internal.DCTL1065, func(e *zerolog.Event) *zerolog.Event {
+		return e.Err(err).Str(field, target)
+	})
+}
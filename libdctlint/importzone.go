@@ -0,0 +1,353 @@
+package libdctlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog"
+)
+
+// defaultImportTTL is the ttl of imported records that do not have one.
+const defaultImportTTL = 3600
+
+// ImportParams describe the template ImportTemplate() creates. Domain is
+// the sample domain the records were taken from. Variables map a
+// variable name to the sample value that is replaced with %name%.
+type ImportParams struct {
+	ProviderID   string
+	ProviderName string
+	ServiceID    string
+	ServiceName  string
+	Domain       string
+	Variables    map[string]string
+}
+
+// TemplateFileName returns the file name 6.11.2. File naming requirements
+// expect for a template.
+func TemplateFileName(template internal.Template) string {
+	return strings.ToLower(template.ProviderID) + "." + strings.ToLower(template.ServiceID) + ".json"
+}
+
+// ParseZoneFragment reads RFC 1035 master file records. Relative names
+// are relative to domain, and owner names in the returned records are
+// fully qualified.
+func ParseZoneFragment(r io.Reader, domain string) ([]internal.ResourceRecord, error) {
+	zp := dns.NewZoneParser(r, dns.Fqdn(domain), "")
+	zp.SetDefaultTTL(defaultImportTTL)
+	var rrs []internal.ResourceRecord
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		hdr := rr.Header()
+		out := internal.ResourceRecord{
+			Type: dns.TypeToString[hdr.Rrtype],
+			Name: strings.ToLower(hdr.Name),
+			TTL:  hdr.Ttl,
+		}
+		switch v := rr.(type) {
+		case *dns.A:
+			out.Data = v.A.String()
+		case *dns.AAAA:
+			out.Data = v.AAAA.String()
+		case *dns.CNAME:
+			out.Data = v.Target
+		case *dns.NS:
+			out.Data = v.Ns
+		case *dns.MX:
+			out.Data = v.Mx
+			out.Priority = uint32(v.Preference)
+		case *dns.SRV:
+			out.Data = v.Target
+			out.Priority = uint32(v.Priority)
+			out.Weight = uint32(v.Weight)
+			out.Port = uint32(v.Port)
+		case *dns.TXT:
+			out.Data = strings.Join(v.Txt, "")
+		default:
+			out.Data = strings.TrimPrefix(rr.String(), hdr.String())
+		}
+		rrs = append(rrs, out)
+	}
+	return rrs, zp.Err()
+}
+
+// ParseRRSetJSON reads records in the template test file format, either
+// as an array or as an object with a records array. Names without a
+// trailing dot are relative to the sample domain.
+func ParseRRSetJSON(r io.Reader) ([]internal.ResourceRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rrs []internal.ResourceRecord
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &rrs)
+	} else {
+		var set struct {
+			Records []internal.ResourceRecord `json:"records"`
+		}
+		err = json.Unmarshal(data, &set)
+		rrs = set.Records
+	}
+	return rrs, err
+}
+
+// relativeName returns a record name relative to domain, or false when
+// the name is not in the domain.
+func relativeName(name, domain string) (string, bool) {
+	if !strings.HasSuffix(name, ".") {
+		return name, true
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	switch {
+	case name == domain:
+		return "@", true
+	case strings.HasSuffix(name, "."+domain):
+		return strings.TrimSuffix(name, "."+domain), true
+	}
+	return "", false
+}
+
+// importSample is a sample value and the variable it is replaced with.
+type importSample struct {
+	value    string
+	variable string
+	// suffix values, the domain, must not be followed by another label
+	suffix bool
+}
+
+// importer turns sample values to template variables. Values are only
+// replaced as whole labels or words, and values found inside a longer
+// label or word are remembered in skipped.
+type importer struct {
+	domain  string
+	samples []importSample
+	skipped []string
+}
+
+func newImporter(params ImportParams) *importer {
+	imp := &importer{domain: strings.ToLower(strings.TrimSuffix(params.Domain, "."))}
+	for name, value := range params.Variables {
+		if value != "" {
+			imp.samples = append(imp.samples, importSample{value: value, variable: "%" + name + "%"})
+		}
+	}
+	if imp.domain != "" {
+		imp.samples = append(imp.samples, importSample{value: imp.domain, variable: "%domain%", suffix: true})
+	}
+	// longest values first, so that a value containing another is
+	// replaced as a whole
+	slices.SortFunc(imp.samples, func(a, b importSample) int {
+		if d := len(b.value) - len(a.value); d != 0 {
+			return d
+		}
+		return strings.Compare(a.variable, b.variable)
+	})
+	return imp
+}
+
+// isWordByte tells if c continues a label or a word.
+func isWordByte(c byte) bool {
+	return c == '-' || c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// boundary tells if the sample found at s[start:end] is not a part of a
+// longer label or word.
+func (sample importSample) boundary(s string, start, end int) bool {
+	if 0 < start && isWordByte(s[start-1]) {
+		return false
+	}
+	if end < len(s) && isWordByte(s[end]) {
+		return false
+	}
+	if sample.suffix && end+1 < len(s) && s[end] == '.' && isWordByte(s[end+1]) {
+		return false
+	}
+	return true
+}
+
+// replace replaces sample values in s with variables in a single pass,
+// so that replaced text is not looked at again.
+func (imp *importer) replace(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		replaced := false
+		for _, sample := range imp.samples {
+			end := i + len(sample.value)
+			if len(s) < end {
+				continue
+			}
+			match := s[i:end] == sample.value
+			if sample.suffix {
+				match = strings.EqualFold(s[i:end], sample.value)
+			}
+			if !match {
+				continue
+			}
+			if !sample.boundary(s, i, end) {
+				if !slices.Contains(imp.skipped, sample.variable) {
+					imp.skipped = append(imp.skipped, sample.variable)
+				}
+				continue
+			}
+			sb.WriteString(sample.variable)
+			i = end
+			replaced = true
+			break
+		}
+		if !replaced {
+			sb.WriteByte(s[i])
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// target converts a host name to a pointsTo or target value, where the
+// domain itself is @.
+func (imp *importer) target(s string) string {
+	s = strings.TrimSuffix(s, ".")
+	if strings.EqualFold(s, imp.domain) {
+		return "@"
+	}
+	return imp.replace(s)
+}
+
+// spfRules returns SPF terms without the version and the all mechanism.
+func spfRules(data string) string {
+	var rules []string
+	for _, term := range strings.Fields(data)[1:] {
+		if strings.HasSuffix(strings.ToLower(term), "all") && len(term) <= 4 {
+			continue
+		}
+		rules = append(rules, term)
+	}
+	return strings.Join(rules, " ")
+}
+
+// ImportTemplate creates a template skeleton of records taken from a
+// sample domain. The sample domain and variable values are replaced with
+// variables, SPF records become SPFM records, and records that cannot be
+// expressed in a template are reported and left out. The template should
+// be written to TemplateFileName() and checked like any other template.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) ImportTemplate(rrs []internal.ResourceRecord, params ImportParams) (internal.Template, exitvals.CheckSeverity) {
+	conf.startCheck()
+	exitVal := exitvals.CheckOK
+	imp := newImporter(params)
+
+	template := internal.Template{
+		ProviderID:   params.ProviderID,
+		ProviderName: params.ProviderName,
+		ServiceID:    params.ServiceID,
+		ServiceName:  params.ServiceName,
+		Version:      1,
+		Records:      internal.Records{},
+	}
+	if template.ProviderName == "" {
+		template.ProviderName = template.ProviderID
+	}
+	if template.ServiceName == "" {
+		template.ServiceName = template.ServiceID
+	}
+
+	for rnum, rr := range rrs {
+		rlog := conf.tlog.With().Int("record", rnum+1).Str("type", rr.Type).Str("name", rr.Name).Logger()
+		name, ok := relativeName(rr.Name, imp.domain)
+		if !ok {
+			exitVal |= conf.emit(rlog, internal.DCTL2028, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("reason", "name is not in "+imp.domain)
+			})
+			continue
+		}
+		ttl := rr.TTL
+		if ttl == 0 {
+			ttl = defaultImportTTL
+		}
+		record := internal.Record{
+			Type: rr.Type,
+			Host: imp.replace(name),
+			TTL:  internal.SINT(strconv.FormatUint(uint64(ttl), 10)),
+		}
+
+		switch rr.Type {
+		case "A", "AAAA", strCNAME, "NS":
+			record.PointsTo = imp.target(rr.Data)
+		case "MX":
+			record.PointsTo = imp.target(rr.Data)
+			record.Priority = internal.SINT(strconv.FormatUint(uint64(rr.Priority), 10))
+		case "SRV":
+			labels := strings.SplitN(name, ".", 3)
+			if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+				exitVal |= conf.emit(rlog, internal.DCTL2028, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("reason", "name is not _service._protocol")
+				})
+				continue
+			}
+			record.Host = ""
+			record.Service = imp.replace(labels[0])
+			record.Protocol = labels[1]
+			record.Name = "@"
+			if len(labels) == 3 {
+				record.Name = imp.replace(labels[2])
+			}
+			record.Target = imp.target(rr.Data)
+			record.Priority = internal.SINT(strconv.FormatUint(uint64(rr.Priority), 10))
+			record.Weight = internal.SINT(strconv.FormatUint(uint64(rr.Weight), 10))
+			record.Port = internal.SINT(strconv.FormatUint(uint64(rr.Port), 10))
+		case "TXT":
+			if isSPF(rr) {
+				record.Type = "SPFM"
+				record.SPFRules = imp.replace(spfRules(rr.Data))
+				break
+			}
+			record.Data = imp.replace(rr.Data)
+		case "SOA", "DNSKEY", "RRSIG", "NSEC", "NSEC3", "NSEC3PARAM":
+			exitVal |= conf.emit(rlog, internal.DCTL2028, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("reason", "zone maintenance record")
+			})
+			continue
+		default:
+			if !slices.Contains(recordTypes, rr.Type) {
+				exitVal |= conf.emit(rlog, internal.DCTL2028, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("reason", "record type is not supported in templates")
+				})
+				continue
+			}
+			record.Data = imp.replace(rr.Data)
+		}
+		if 0 < len(imp.skipped) {
+			rlog.Warn().Strs("variables", imp.skipped).Msg("sample value is a part of a longer label or word and was not replaced")
+			imp.skipped = nil
+		}
+		template.Records = append(template.Records, record)
+	}
+
+	if len(template.Records) == 0 {
+		conf.tlog.Error().Msg("no records to import")
+		exitVal |= exitvals.CheckError
+	}
+	return template, exitVal
+}
+
+// FormatTemplate returns template json the way -pretty prints it.
+func FormatTemplate(template internal.Template, indent uint) ([]byte, error) {
+	marshaled, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, marshaled, "", strings.Repeat(" ", int(indent))); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
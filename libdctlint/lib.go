@@ -73,27 +73,40 @@ func (cw *captureWriter) Write(p []byte) (int, error) {
 // GetAndCheckTemplate) so it is stored in conf.messages; no output reaches
 // the zerolog global logger.
 func (conf *Conf) emit(logger zerolog.Logger, dctl internal.DCTL, fn func(*zerolog.Event) *zerolog.Event) exitvals.CheckSeverity {
-	e := logger.WithLevel(dctl.Level())
+	return conf.emitLevel(logger, dctl, dctl.Level(), fn)
+}
+
+// emitLevel is the same as emit, but the DCTL code is logged at the given
+// level instead of the level defined for the code.
+func (conf *Conf) emitLevel(logger zerolog.Logger, dctl internal.DCTL, level zerolog.Level, fn func(*zerolog.Event) *zerolog.Event) exitvals.CheckSeverity {
+	e := logger.WithLevel(level)
 	if fn != nil {
 		e = fn(e)
 	}
 	e.EmbedObject(dctl).Msg("")
-	return dctl.Severity()
+	return internal.LevelSeverity(level)
 }
 
-// GetAndCheckTemplate is used in dctweb. Do not use applications
-// outside of this project.
-func (conf *Conf) GetAndCheckTemplate(f *bufio.Reader) (internal.Template, exitvals.CheckSeverity) {
-	// Reset per-call message list
+// startCheck resets the per-call message list and sets up the template
+// logger. In library mode the logger writes to a captureWriter.
+func (conf *Conf) startCheck() {
 	conf.messages = nil
+	conf.SetLogger(conf.baseLogger().With().Str("template", conf.fileName).Logger())
+}
 
+// baseLogger returns the global logger, or in library mode a logger that
+// writes to a captureWriter.
+func (conf *Conf) baseLogger() zerolog.Logger {
 	if conf.lib {
-		cw := &captureWriter{conf: conf}
-		conf.SetLogger(zerolog.New(cw).With().Str("template", conf.fileName).Logger())
-	} else {
-		conf.SetLogger(log.With().Str("template", conf.fileName).Logger())
+		return zerolog.New(&captureWriter{conf: conf})
 	}
-	conf.tlog.Debug().Msg("starting template check")
+	return log.Logger
+}
+
+// ReadTemplate decodes a template without checking it. Decoding errors
+// are reported as DCTL0003 and result to exitvals.CheckFatal.
+func (conf *Conf) ReadTemplate(f *bufio.Reader) (internal.Template, exitvals.CheckSeverity) {
+	conf.startCheck()
 
 	// Decode json
 	decoder := json.NewDecoder(f)
@@ -106,7 +119,19 @@ func (conf *Conf) GetAndCheckTemplate(f *bufio.Reader) (internal.Template, exitv
 		})
 		return template, exitvals.CheckFatal
 	}
-	exitVal := conf.checkTemplate(template)
+	return template, exitvals.CheckOK
+}
+
+// GetAndCheckTemplate is used in dctweb. Do not use applications
+// outside of this project.
+func (conf *Conf) GetAndCheckTemplate(f *bufio.Reader) (internal.Template, exitvals.CheckSeverity) {
+	template, exitVal := conf.ReadTemplate(f)
+	if exitVal != exitvals.CheckOK {
+		return template, exitVal
+	}
+	conf.tlog.Debug().Msg("starting template check")
+	exitVal = conf.checkTemplate(template)
+	conf.addToCatalog(template, exitVal)
 	return template, exitVal
 }
 
@@ -139,7 +164,7 @@ func (conf *Conf) checkTemplate(template internal.Template) exitvals.CheckSeveri
 
 	// Check 6.11.2. File naming requirements
 	if conf.fileName != "/dev/stdin" {
-		expected := strings.ToLower(template.ProviderID) + "." + strings.ToLower(template.ServiceID) + ".json"
+		expected := TemplateFileName(template)
 		if filepath.Base(conf.fileName) != expected {
 			exitVal |= conf.emit(conf.tlog, internal.DCTL1003, func(e *zerolog.Event) *zerolog.Event {
 				return e.Str("expected", expected)
@@ -148,15 +173,18 @@ func (conf *Conf) checkTemplate(template internal.Template) exitvals.CheckSeveri
 	}
 
 	// Detect ID collisions _across multiple_ templates
-	if _, found := conf.collision[template.ProviderID+"/"+template.ServiceID]; found {
+	key := collisionKey(template)
+	if first, found := conf.collision[key]; found {
 		exitVal |= conf.emit(conf.tlog, internal.DCTL1004, func(e *zerolog.Event) *zerolog.Event {
-			return e.Str("providerId", template.ProviderID).Str("serviceId", template.ServiceID)
+			return e.Str("providerId", template.ProviderID).Str("serviceId", template.ServiceID).Str("first", first)
 		})
+	} else {
+		conf.collision[key] = conf.fileName
 	}
-	conf.collision[template.ProviderID+"/"+template.ServiceID] = true
+	conf.trackProvider(template)
 
 	// Check 'validate:' fields in internal/json.go definitions
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate := newValidator()
 	err := validate.Struct(template)
 	if err != nil {
 		for _, verr := range err.(validator.ValidationErrors) {
@@ -234,9 +262,9 @@ func (conf *Conf) checkTemplate(template internal.Template) exitvals.CheckSeveri
 	}
 
 	// DNS provider specific checks
-	if conf.cloudflare {
-		conf.tlog.Debug().Msg("performing Cloudflare checks")
-		exitVal |= conf.cloudflareTemplateChecks(template)
+	if conf.profile != nil {
+		conf.tlog.Debug().Str("profile", conf.profile.Name).Msg("performing DNS provider profile checks")
+		exitVal |= conf.profileTemplateChecks(template)
 	}
 
 	// Template records checks
@@ -288,6 +316,11 @@ func (conf *Conf) checkTemplate(template internal.Template) exitvals.CheckSeveri
 		}
 	}
 
+	exitVal |= conf.checkRedirConflicts(template)
+	exitVal |= conf.checkNameTree(template)
+	exitVal |= conf.checkGroups(template)
+	exitVal |= conf.checkMultiInstance(template)
+
 	// Pretty printing and/or inplace write output
 	if conf.prettyPrint || conf.inplace {
 		if conf.increment {
@@ -297,33 +330,19 @@ func (conf *Conf) checkTemplate(template internal.Template) exitvals.CheckSeveri
 		// Remove white spaces, see DCTL1026
 		template.SyncRedirectDomain = internal.StripSpaces(template.SyncRedirectDomain)
 
-		// Convert to json
-		marshaled, err := json.Marshal(template)
-		if err != nil {
-			conf.emit(conf.tlog, internal.DCTL0003, func(e *zerolog.Event) *zerolog.Event {
-				return e.Err(err)
-			})
-			return exitVal | exitvals.CheckError
-		}
-
-		// Make output pretty
-		var out bytes.Buffer
-		err = json.Indent(&out, marshaled, "", strings.Repeat(" ", int(conf.indent)))
+		// Convert to pretty json
+		pretty, err := FormatTemplate(template, conf.indent)
 		if err != nil {
 			conf.emit(conf.tlog, internal.DCTL0003, func(e *zerolog.Event) *zerolog.Event {
 				return e.Err(err)
 			})
 			return exitVal | exitvals.CheckError
 		}
-		_, err = fmt.Fprintf(&out, "\n")
-		if err != nil {
-			conf.tlog.Error().Err(err).Msg("could not print to output")
-			exitVal += exitvals.CheckFatal
-		}
+		out := bytes.NewBuffer(pretty)
 
 		// Decide where to write
 		if conf.inplace {
-			exitVal |= conf.writeBack(out)
+			exitVal |= conf.writeBack(*out)
 		} else {
 			_, err = out.WriteTo(os.Stdout)
 			if err != nil {
@@ -424,10 +443,22 @@ func isVariable(s string) bool {
 	return strings.Count(s, "%") > 1
 }
 
-func (conf *Conf) checkSyncRedirectDomain(srd string) (err error) {
+// syncRedirectDomains returns the syncRedirectDomain list entries with
+// surrounding whitespace removed.
+func syncRedirectDomains(srd string) []string {
+	if srd == "" {
+		return nil
+	}
 	srdList := strings.Split(srd, ",")
 	for i := range srdList {
-		trimmed := strings.TrimSpace(srdList[i])
+		srdList[i] = strings.TrimSpace(srdList[i])
+	}
+	return srdList
+}
+
+func (conf *Conf) checkSyncRedirectDomain(srd string) (err error) {
+	srdList := strings.Split(srd, ",")
+	for i, trimmed := range syncRedirectDomains(srd) {
 		if trimmed != srdList[i] {
 			conf.emit(conf.tlog, internal.DCTL1026, func(e *zerolog.Event) *zerolog.Event {
 				return e.Str("domain", srdList[i])
@@ -456,29 +487,3 @@ func checkFQDN(fqdn string) error {
 	}
 	return nil
 }
-
-func (conf *Conf) cloudflareTemplateChecks(template internal.Template) exitvals.CheckSeverity {
-	exitVal := exitvals.CheckOK
-	if template.SyncBlock {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5000, nil)
-	}
-	if template.SyncPubKeyDomain == "" {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5001, nil)
-	}
-	if template.SharedServiceName {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5002, nil)
-	}
-	if template.SyncRedirectDomain != "" {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5003, nil)
-	}
-	if template.MultiInstance {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5004, nil)
-	}
-	if template.WarnPhishing {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5005, nil)
-	}
-	if template.HostRequired {
-		exitVal |= conf.emit(conf.tlog, internal.DCTL5006, nil)
-	}
-	return exitVal
-}
@@ -0,0 +1,112 @@
+package libdctlint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// instanceRecords renders every template record of one simulated
+// application. Each instance uses the same domain, and its own value for
+// every variable. A hostRequired template is applied to a different host
+// each time, so each instance gets its own host as well. CheckConflicts()
+// uses template index as instance to keep variables of different
+// templates apart.
+func instanceRecords(template internal.Template, instance int) []internal.ResourceRecord {
+	params := ApplyParams{
+		Domain:    "example.com",
+		Variables: make(map[string]string),
+	}
+	if template.HostRequired {
+		params.Host = fmt.Sprintf("host%d", instance)
+	}
+	for name := range templateVariables(template, nil) {
+		params.Variables[name] = fmt.Sprintf("instance%d", instance)
+	}
+	r := newRenderer(params)
+	rrs := make([]internal.ResourceRecord, len(template.Records))
+	for rnum, record := range template.Records {
+		rrs[rnum] = r.resourceRecord(record)
+	}
+	return rrs
+}
+
+// instanceConflict tells if applying record b deletes record a at the
+// same name, following the Domain Connect conflict rules.
+func instanceConflict(a, b internal.ResourceRecord, record internal.Record) bool {
+	switch b.Type {
+	case "NS":
+		return true
+	case "A", "AAAA":
+		switch a.Type {
+		case "A", "AAAA", "REDIR301", "REDIR302":
+			return true
+		}
+	case "MX", "SRV":
+		return a.Type == b.Type
+	case "REDIR301", "REDIR302":
+		switch a.Type {
+		case "A", "AAAA", "REDIR301", "REDIR302":
+			return true
+		}
+	case "TXT":
+		if a.Type != "TXT" {
+			return false
+		}
+		switch record.TxtCMM {
+		case "All":
+			return true
+		case "Prefix":
+			return strings.HasPrefix(a.Data, record.TxtCMP)
+		}
+	}
+	return false
+}
+
+// checkMultiInstance applies a multiInstance template twice with distinct
+// variable values, and reports records that make repeated use unsafe.
+// Instances of a hostRequired template are applied to different hosts, so
+// their records cannot collide and such templates are not analysed.
+func (conf *Conf) checkMultiInstance(template internal.Template) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	if !template.MultiInstance || template.HostRequired {
+		return exitVal
+	}
+	first := instanceRecords(template, 1)
+	second := instanceRecords(template, 2)
+
+	// record pairs are reported once, in either order
+	seen := make(map[[2]int]bool)
+	for j, b := range second {
+		record := template.Records[j]
+		rlog := conf.tlog.With().Str("groupid", record.GroupID).Int("record", j+1).Str("type", record.Type).Logger()
+		for i, a := range first {
+			pair := [2]int{min(i, j), max(i, j)}
+			if seen[pair] || !strings.EqualFold(a.Name, b.Name) {
+				continue
+			}
+			switch {
+			case i == j && a == b:
+				exitVal |= conf.emit(rlog, internal.DCTL1083, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("name", b.Name)
+				})
+			case a.Type == strCNAME || b.Type == strCNAME:
+				exitVal |= conf.emit(rlog, internal.DCTL1084, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("name", b.Name).Int("otherrecord", i+1).Str("othertype", a.Type)
+				})
+			case instanceConflict(a, b, record) || instanceConflict(b, a, template.Records[i]):
+				exitVal |= conf.emit(rlog, internal.DCTL1082, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("name", b.Name).Int("otherrecord", i+1).Str("othertype", a.Type)
+				})
+			default:
+				continue
+			}
+			seen[pair] = true
+		}
+	}
+	return exitVal
+}
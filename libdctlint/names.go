@@ -0,0 +1,111 @@
+package libdctlint
+
+import (
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	maxLabelLength = 63
+	maxNameLength  = 253
+	// DefaultVariableLength is the default worst case length of a
+	// variable value in name length checks.
+	DefaultVariableLength = 32
+)
+
+// worstCaseName substitutes variables in a name with placeholders of the
+// configured worst case length. The %fqdn% variable is host parameter and
+// domain joined with a dot.
+func (conf *Conf) worstCaseName(name string) string {
+	fill := strings.Repeat("x", int(conf.varLength))
+	return variableRe.ReplaceAllStringFunc(name, func(v string) string {
+		if strings.EqualFold(v, "%fqdn%") {
+			return fill + "." + fill
+		}
+		return fill
+	})
+}
+
+// ownerName returns the record owner name relative to the domain, before
+// variable substitution. The domain itself is always @.
+func ownerName(record *internal.Record) string {
+	if record.Type != "SRV" {
+		if record.Host == "" {
+			return "@"
+		}
+		return record.Host
+	}
+	labels := []string{
+		"_" + strings.TrimPrefix(record.Service, "_"),
+		"_" + strings.TrimPrefix(record.Protocol, "_"),
+	}
+	host := record.Name
+	if host == "" {
+		host = record.Host
+	}
+	if host != "" && host != "@" {
+		labels = append(labels, host)
+	}
+	return strings.Join(labels, ".")
+}
+
+// checkNameLength checks name labels and total length fit DNS limits.
+// Relative names are extended with the host parameter and the domain that
+// both count as a variable.
+func (conf *Conf) checkNameLength(field, name string, relative bool, rlog zerolog.Logger) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || name == "@" || name == "*" {
+		if !relative {
+			return exitVal
+		}
+		name = ""
+	}
+
+	overflow := 0
+	for label := range strings.SplitSeq(name, ".") {
+		if !strings.Contains(label, "%") {
+			if maxLabelLength < len(label) {
+				exitVal |= conf.emit(rlog, internal.DCTL1062, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str(field, name).Str("label", label).Int("length", len(label))
+				})
+			}
+			continue
+		}
+		for wl := range strings.SplitSeq(conf.worstCaseName(label), ".") {
+			overflow = max(overflow, len(wl))
+		}
+	}
+	if maxLabelLength < overflow {
+		exitVal |= conf.emit(rlog, internal.DCTL1064, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str(field, name).Int("length", overflow).Uint("varlength", conf.varLength)
+		})
+	}
+
+	worstCase := conf.worstCaseName(name)
+	if relative {
+		worstCase = strings.Trim(worstCase+"."+conf.worstCaseName("%host%.%domain%"), ".")
+	}
+	if maxNameLength < len(worstCase) {
+		exitVal |= conf.emit(rlog, internal.DCTL1063, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str(field, name).Int("length", len(worstCase)).Uint("varlength", conf.varLength)
+		})
+	}
+	return exitVal
+}
+
+// checkRecordNames checks owner name and target lengths of a record.
+func (conf *Conf) checkRecordNames(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	exitVal := conf.checkNameLength("host", ownerName(record), true, rlog)
+	switch record.Type {
+	case strCNAME, "NS", "MX":
+		exitVal |= conf.checkNameLength("pointsTo", record.PointsTo, false, rlog)
+	case "SRV":
+		exitVal |= conf.checkNameLength("target", record.Target, false, rlog)
+	}
+	return exitVal
+}
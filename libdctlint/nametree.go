@@ -0,0 +1,134 @@
+package libdctlint
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// nameNode is a label in the tree of template record owner names. The
+// root node is the apex, host @.
+type nameNode struct {
+	name     string
+	children map[string]*nameNode
+	records  []int
+}
+
+func (n *nameNode) child(label string) *nameNode {
+	if n.children == nil {
+		n.children = make(map[string]*nameNode)
+	}
+	c, ok := n.children[label]
+	if !ok {
+		name := label
+		if n.name != "@" {
+			name = label + "." + n.name
+		}
+		c = &nameNode{name: name}
+		n.children[label] = c
+	}
+	return c
+}
+
+// buildNameTree inserts template records to a tree by owner name labels.
+// Variables are kept as opaque labels.
+func buildNameTree(template internal.Template) *nameNode {
+	root := &nameNode{name: "@"}
+	for rnum, record := range template.Records {
+		node := root
+		owner := strings.ToLower(strings.TrimSuffix(ownerName(&record), "."))
+		if owner != "" && owner != "@" {
+			labels := strings.Split(owner, ".")
+			for i := len(labels) - 1; 0 <= i; i-- {
+				node = node.child(labels[i])
+			}
+		}
+		node.records = append(node.records, rnum)
+	}
+	return root
+}
+
+// hasType tells if any of the records at node is of the type.
+func (n *nameNode) hasType(template internal.Template, recordType string) bool {
+	for _, rnum := range n.records {
+		if template.Records[rnum].Type == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// isGlue tells if an address record name is used as an NS target, such
+// as ns1.sub for delegation of sub.
+func isGlue(template internal.Template, record internal.Record, name string) bool {
+	if record.Type != "A" && record.Type != "AAAA" {
+		return false
+	}
+	for _, ns := range template.Records {
+		if ns.Type == "NS" && strings.HasPrefix(strings.ToLower(ns.PointsTo), name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNameTree reports records hidden below NS delegations, wildcards
+// that do not match names defined explicitly next to them, and CNAMEs at
+// names that have child records.
+func (conf *Conf) checkNameTree(template internal.Template) exitvals.CheckSeverity {
+	return conf.walkNameTree(template, buildNameTree(template), "")
+}
+
+func (conf *Conf) walkNameTree(template internal.Template, node *nameNode, cut string) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	recordLog := func(rnum int) zerolog.Logger {
+		record := template.Records[rnum]
+		return conf.tlog.With().Str("groupid", record.GroupID).Int("record", rnum+1).Str("type", record.Type).Logger()
+	}
+
+	atCut := cut == "" && node.hasType(template, "NS")
+	for _, rnum := range node.records {
+		record := template.Records[rnum]
+		occluded := cut != "" && !isGlue(template, record, node.name)
+		if atCut && record.Type != "NS" && record.Type != "DS" {
+			occluded = true
+		}
+		if occluded {
+			delegation := cut
+			if delegation == "" {
+				delegation = node.name
+			}
+			exitVal |= conf.emit(recordLog(rnum), internal.DCTL1074, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("host", node.name).Str("delegation", delegation)
+			})
+		}
+		if record.Type == strCNAME && 0 < len(node.children) {
+			exitVal |= conf.emit(recordLog(rnum), internal.DCTL1076, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("host", node.name).Strs("children", sortedKeys(node.children))
+			})
+		}
+	}
+
+	if wildcard, ok := node.children["*"]; ok && 1 < len(node.children) {
+		explicit := slices.DeleteFunc(sortedKeys(node.children), func(label string) bool {
+			return label == "*"
+		})
+		for _, rnum := range wildcard.records {
+			exitVal |= conf.emit(recordLog(rnum), internal.DCTL1075, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("host", wildcard.name).Strs("explicit", explicit)
+			})
+		}
+	}
+
+	if atCut {
+		cut = node.name
+	}
+	for _, label := range sortedKeys(node.children) {
+		exitVal |= conf.walkNameTree(template, node.children[label], cut)
+	}
+	return exitVal
+}
@@ -0,0 +1,240 @@
+package libdctlint
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// ProfileRule is a DNS provider restriction a Profile can declare.
+type ProfileRule uint8
+
+const (
+	// RuleRecordType reports record types missing from Profile.RecordTypes
+	RuleRecordType ProfileRule = iota + 1
+	// RuleSyncBlock reports templates using syncBlock
+	RuleSyncBlock
+	// RuleSyncPubKeyDomain reports templates without syncPubKeyDomain
+	RuleSyncPubKeyDomain
+	// RuleSharedServiceName reports templates using sharedServiceName
+	RuleSharedServiceName
+	// RuleSyncRedirectDomain reports templates using syncRedirectDomain
+	RuleSyncRedirectDomain
+	// RuleMultiInstance reports templates using multiInstance
+	RuleMultiInstance
+	// RuleWarnPhishing reports templates using warnPhishing
+	RuleWarnPhishing
+	// RuleHostRequired reports templates using hostRequired
+	RuleHostRequired
+	// RuleEssential reports records using essential
+	RuleEssential
+	// RuleTxtConflictMatching reports TXT records using conflict matching
+	RuleTxtConflictMatching
+	// RuleApexCNAME reports CNAME and NS records at the apex
+	RuleApexCNAME
+	// RuleMinTTL reports ttl values below Profile.MinTTL
+	RuleMinTTL
+	// RuleMaxTTL reports ttl values above Profile.MaxTTL
+	RuleMaxTTL
+	// RuleApexCNAMEType reports all APEXCNAME records, instead of
+	// RuleRecordType
+	RuleApexCNAMEType
+)
+
+// ruleNames are the ProfileRule names used in profile files
+var ruleNames = map[string]ProfileRule{
+	"recordType":          RuleRecordType,
+	"syncBlock":           RuleSyncBlock,
+	"syncPubKeyDomain":    RuleSyncPubKeyDomain,
+	"sharedServiceName":   RuleSharedServiceName,
+	"syncRedirectDomain":  RuleSyncRedirectDomain,
+	"multiInstance":       RuleMultiInstance,
+	"warnPhishing":        RuleWarnPhishing,
+	"hostRequired":        RuleHostRequired,
+	"essential":           RuleEssential,
+	"txtConflictMatching": RuleTxtConflictMatching,
+	"cnameAtApex":         RuleApexCNAME,
+	"minTTL":              RuleMinTTL,
+	"maxTTL":              RuleMaxTTL,
+	"apexcnameType":       RuleApexCNAMEType,
+}
+
+func (rule ProfileRule) String() string {
+	for name, r := range ruleNames {
+		if r == rule {
+			return name
+		}
+	}
+	return fmt.Sprintf("rule%d", rule)
+}
+
+// recordTypes are the record types checkRecord() knows about
+var recordTypes = []string{
+	"A",
+	"AAAA",
+	"APEXCNAME",
+	"CAA",
+	"CNAME",
+	"DS",
+	"HTTPS",
+	"MX",
+	"NS",
+	"REDIR301",
+	"REDIR302",
+	"SMIMEA",
+	"SPFM",
+	"SRV",
+	"SSHFP",
+	"SVCB",
+	"TLSA",
+	"TXT",
+	"URI",
+}
+
+// Profile describes DNS provider specific template restrictions. A rule is
+// enforced when Codes has a DCTL code for it, and each profile should use
+// its own DCTL code range. Levels can be used to override the log level
+// defined for a code.
+type Profile struct {
+	Name        string
+	Description string
+	// RecordTypes lists supported record types, RuleRecordType is not
+	// checked when the list is empty
+	RecordTypes []string
+	MinTTL      uint32
+	MaxTTL      uint32
+	Codes       map[ProfileRule]internal.DCTL
+	Levels      map[ProfileRule]zerolog.Level
+}
+
+// profiles is the registry of DNS provider profiles
+var profiles = map[string]*Profile{
+	cloudflareProfile.Name: &cloudflareProfile,
+}
+
+// RegisterProfile adds a profile to the registry. Profile names must be
+// unique.
+func RegisterProfile(p *Profile) error {
+	if p.Name == "" {
+		return errors.New("profile name must not be empty")
+	}
+	if _, found := profiles[p.Name]; found {
+		return fmt.Errorf("profile '%s' is already registered", p.Name)
+	}
+	profiles[p.Name] = p
+	return nil
+}
+
+// LookupProfile returns a registered profile.
+func LookupProfile(name string) (*Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// ProfileNames returns registered profile names in sorted order.
+func ProfileNames() []string {
+	return sortedKeys(profiles)
+}
+
+func (p *Profile) has(rule ProfileRule) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.Codes[rule]
+	return ok
+}
+
+// supportsType tells if a record type is supported by the profile. Types
+// checkRecord() does not know are reported as such, not by the profile.
+func (p *Profile) supportsType(recordType string) bool {
+	recordType = strings.ToUpper(recordType)
+	if recordType == "APEXCNAME" && p.has(RuleApexCNAMEType) {
+		return false
+	}
+	if !p.has(RuleRecordType) || len(p.RecordTypes) == 0 {
+		return true
+	}
+	if !slices.Contains(recordTypes, recordType) {
+		return true
+	}
+	return slices.Contains(p.RecordTypes, recordType)
+}
+
+// typeRule returns the rule reporting an unsupported record type.
+func (p *Profile) typeRule(recordType string) ProfileRule {
+	if strings.EqualFold(recordType, "APEXCNAME") && p.has(RuleApexCNAMEType) {
+		return RuleApexCNAMEType
+	}
+	return RuleRecordType
+}
+
+// profileEmit emits the profile DCTL code of rule when the active profile
+// enforces the rule. The profile name is added to the message.
+func (conf *Conf) profileEmit(logger zerolog.Logger, rule ProfileRule, fn func(*zerolog.Event) *zerolog.Event) exitvals.CheckSeverity {
+	if !conf.profile.has(rule) {
+		return exitvals.CheckOK
+	}
+	dctl := conf.profile.Codes[rule]
+	level, ok := conf.profile.Levels[rule]
+	if !ok {
+		level = dctl.Level()
+	}
+	return conf.emitLevel(logger, dctl, level, func(e *zerolog.Event) *zerolog.Event {
+		e = e.Str("profile", conf.profile.Name)
+		if fn != nil {
+			e = fn(e)
+		}
+		return e
+	})
+}
+
+func (conf *Conf) profileTemplateChecks(template internal.Template) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	if template.SyncBlock {
+		exitVal |= conf.profileEmit(conf.tlog, RuleSyncBlock, nil)
+	}
+	if template.SyncPubKeyDomain == "" {
+		exitVal |= conf.profileEmit(conf.tlog, RuleSyncPubKeyDomain, nil)
+	}
+	if template.SharedServiceName {
+		exitVal |= conf.profileEmit(conf.tlog, RuleSharedServiceName, nil)
+	}
+	if template.SyncRedirectDomain != "" {
+		exitVal |= conf.profileEmit(conf.tlog, RuleSyncRedirectDomain, nil)
+	}
+	if template.MultiInstance {
+		exitVal |= conf.profileEmit(conf.tlog, RuleMultiInstance, nil)
+	}
+	if template.WarnPhishing {
+		exitVal |= conf.profileEmit(conf.tlog, RuleWarnPhishing, nil)
+	}
+	if template.HostRequired {
+		exitVal |= conf.profileEmit(conf.tlog, RuleHostRequired, nil)
+	}
+	return exitVal
+}
+
+// profileTTLChecks compares a literal ttl against the profile bounds.
+func (conf *Conf) profileTTLChecks(ttl uint32, rlog zerolog.Logger) exitvals.CheckSeverity {
+	if conf.profile == nil {
+		return exitvals.CheckOK
+	}
+	exitVal := exitvals.CheckOK
+	if ttl < conf.profile.MinTTL {
+		exitVal |= conf.profileEmit(rlog, RuleMinTTL, func(e *zerolog.Event) *zerolog.Event {
+			return e.Uint32("ttl", ttl).Uint32("min", conf.profile.MinTTL)
+		})
+	}
+	if 0 < conf.profile.MaxTTL && conf.profile.MaxTTL < ttl {
+		exitVal |= conf.profileEmit(rlog, RuleMaxTTL, func(e *zerolog.Event) *zerolog.Event {
+			return e.Uint32("ttl", ttl).Uint32("max", conf.profile.MaxTTL)
+		})
+	}
+	return exitVal
+}
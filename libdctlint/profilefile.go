@@ -0,0 +1,173 @@
+package libdctlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// profileFile is the on-disk format of a DNS provider profile. The
+// APEXCNAME and Redirect fields are shorthands to remove the types from
+// RecordTypes. An empty RecordTypes means all types checkRecord() knows.
+type profileFile struct {
+	Name                    string            `json:"name" yaml:"name"`
+	Description             string            `json:"description" yaml:"description"`
+	RecordTypes             []string          `json:"recordTypes" yaml:"recordTypes"`
+	ForbiddenFlags          []string          `json:"forbiddenFlags" yaml:"forbiddenFlags"`
+	RequireSyncPubKeyDomain bool              `json:"requireSyncPubKeyDomain" yaml:"requireSyncPubKeyDomain"`
+	MinTTL                  uint32            `json:"minTTL" yaml:"minTTL"`
+	MaxTTL                  uint32            `json:"maxTTL" yaml:"maxTTL"`
+	APEXCNAME               *bool             `json:"apexCname" yaml:"apexCname"`
+	Redirect                *bool             `json:"redirect" yaml:"redirect"`
+	Severity                map[string]string `json:"severity" yaml:"severity"`
+}
+
+// profileFileCodes are the generic DCTL codes of profiles loaded from files
+var profileFileCodes = map[ProfileRule]internal.DCTL{
+	RuleRecordType:          internal.DCTL6000,
+	RuleSyncBlock:           internal.DCTL6001,
+	RuleSyncPubKeyDomain:    internal.DCTL6002,
+	RuleSharedServiceName:   internal.DCTL6003,
+	RuleSyncRedirectDomain:  internal.DCTL6004,
+	RuleMultiInstance:       internal.DCTL6005,
+	RuleWarnPhishing:        internal.DCTL6006,
+	RuleHostRequired:        internal.DCTL6007,
+	RuleEssential:           internal.DCTL6008,
+	RuleTxtConflictMatching: internal.DCTL6009,
+	RuleApexCNAME:           internal.DCTL6010,
+	RuleMinTTL:              internal.DCTL6011,
+	RuleMaxTTL:              internal.DCTL6012,
+}
+
+// flagRules are the rules that can be listed in forbiddenFlags
+var flagRules = []ProfileRule{
+	RuleSyncBlock,
+	RuleSharedServiceName,
+	RuleSyncRedirectDomain,
+	RuleMultiInstance,
+	RuleWarnPhishing,
+	RuleHostRequired,
+	RuleEssential,
+	RuleTxtConflictMatching,
+	RuleApexCNAME,
+}
+
+// LoadProfileFile reads a DNS provider profile from a json or yaml file.
+// Files with .yaml or .yml suffix are read as yaml, others as json. The
+// returned profile is not registered, see RegisterProfile().
+func LoadProfileFile(fileName string) (*Profile, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var pf profileFile
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&pf)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&pf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	p, err := pf.profile()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return p, nil
+}
+
+func (pf *profileFile) profile() (*Profile, error) {
+	if pf.Name == "" {
+		return nil, fmt.Errorf("profile name must not be empty")
+	}
+	if 0 < pf.MaxTTL && pf.MaxTTL < pf.MinTTL {
+		return nil, fmt.Errorf("maxTTL %d is smaller than minTTL %d", pf.MaxTTL, pf.MinTTL)
+	}
+
+	p := &Profile{
+		Name:        pf.Name,
+		Description: pf.Description,
+		MinTTL:      pf.MinTTL,
+		MaxTTL:      pf.MaxTTL,
+		Codes:       make(map[ProfileRule]internal.DCTL),
+		Levels:      make(map[ProfileRule]zerolog.Level),
+	}
+	enable := func(rule ProfileRule) {
+		p.Codes[rule] = profileFileCodes[rule]
+	}
+
+	// Record types
+	p.RecordTypes = slices.Clone(recordTypes)
+	if 0 < len(pf.RecordTypes) {
+		p.RecordTypes = p.RecordTypes[:0]
+		for _, t := range pf.RecordTypes {
+			t = strings.ToUpper(t)
+			if !slices.Contains(recordTypes, t) {
+				return nil, fmt.Errorf("unknown record type '%s'", t)
+			}
+			p.RecordTypes = append(p.RecordTypes, t)
+		}
+	}
+	if pf.APEXCNAME != nil && !*pf.APEXCNAME {
+		p.RecordTypes = slices.DeleteFunc(p.RecordTypes, func(t string) bool {
+			return t == "APEXCNAME"
+		})
+	}
+	if pf.Redirect != nil && !*pf.Redirect {
+		p.RecordTypes = slices.DeleteFunc(p.RecordTypes, func(t string) bool {
+			return t == "REDIR301" || t == "REDIR302"
+		})
+	}
+	enable(RuleRecordType)
+
+	// Flags
+	for _, flag := range pf.ForbiddenFlags {
+		rule, ok := ruleNames[flag]
+		if !ok || !slices.Contains(flagRules, rule) {
+			return nil, fmt.Errorf("unknown forbidden flag '%s'", flag)
+		}
+		enable(rule)
+	}
+	if pf.RequireSyncPubKeyDomain {
+		enable(RuleSyncPubKeyDomain)
+	}
+
+	// TTL bounds
+	if 0 < pf.MinTTL {
+		enable(RuleMinTTL)
+	}
+	if 0 < pf.MaxTTL {
+		enable(RuleMaxTTL)
+	}
+
+	// Severity overrides
+	for name, levelName := range pf.Severity {
+		rule, ok := ruleNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown severity rule '%s'", name)
+		}
+		level, err := zerolog.ParseLevel(levelName)
+		if err != nil || level == zerolog.NoLevel {
+			return nil, fmt.Errorf("invalid severity '%s' for rule '%s'", levelName, name)
+		}
+		p.Levels[rule] = level
+	}
+
+	return p, nil
+}
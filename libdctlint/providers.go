@@ -0,0 +1,102 @@
+package libdctlint
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// providerTemplate holds the fields of a checked template that must agree
+// with other templates of the same provider.
+type providerTemplate struct {
+	file             string
+	providerID       string
+	providerName     string
+	syncPubKeyDomain string
+	logoHost         string
+}
+
+// trackProvider remembers a checked template for CheckProviders().
+// Providers are grouped case-insensitively, like template file names.
+func (conf *Conf) trackProvider(template internal.Template) {
+	if conf.providers == nil {
+		conf.providers = make(map[string][]providerTemplate)
+	}
+	pt := providerTemplate{
+		file:             conf.fileName,
+		providerID:       template.ProviderID,
+		providerName:     template.ProviderName,
+		syncPubKeyDomain: strings.ToLower(strings.TrimSuffix(template.SyncPubKeyDomain, ".")),
+	}
+	if u, err := url.Parse(template.Logo); err == nil {
+		pt.logoHost = strings.ToLower(u.Hostname())
+	}
+	key := strings.ToLower(template.ProviderID)
+	if _, found := conf.providers[key]; !found {
+		conf.providerOrder = append(conf.providerOrder, key)
+	}
+	conf.providers[key] = append(conf.providers[key], pt)
+}
+
+// commonValue returns the value most templates use, the first one seen
+// wins a tie. Empty values are ignored.
+func commonValue(pts []providerTemplate, field func(providerTemplate) string) string {
+	counts := make(map[string]int)
+	common := ""
+	for _, pt := range pts {
+		value := field(pt)
+		if value == "" {
+			continue
+		}
+		counts[value]++
+		if counts[common] < counts[value] {
+			common = value
+		}
+	}
+	return common
+}
+
+// CheckProviders compares templates of each provider checked since
+// NewConf(), and reports differences in providerName, syncPubKeyDomain,
+// and logoUrl host. Call this after all templates are checked. serviceIds
+// that differ only in case are DCTL1004 collisions of CheckTemplate().
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) CheckProviders() exitvals.CheckSeverity {
+	conf.messages = nil
+	logger := conf.baseLogger()
+	exitVal := exitvals.CheckOK
+
+	fields := []struct {
+		dctl  internal.DCTL
+		name  string
+		value func(providerTemplate) string
+	}{
+		{internal.DCTL3020, "providerName", func(pt providerTemplate) string { return pt.providerName }},
+		{internal.DCTL3021, "syncPubKeyDomain", func(pt providerTemplate) string { return pt.syncPubKeyDomain }},
+		{internal.DCTL3022, "logoHost", func(pt providerTemplate) string { return pt.logoHost }},
+	}
+
+	for _, key := range conf.providerOrder {
+		pts := conf.providers[key]
+		for _, field := range fields {
+			common := commonValue(pts, field.value)
+			for _, pt := range pts {
+				value := field.value(pt)
+				if value == "" || value == common {
+					continue
+				}
+				exitVal |= conf.emit(logger, field.dctl, func(e *zerolog.Event) *zerolog.Event {
+					return e.Str("template", pt.file).Str("providerId", pt.providerID).
+						Str(field.name, value).Str("expected", common)
+				})
+			}
+		}
+	}
+	return exitVal
+}
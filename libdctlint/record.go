@@ -38,6 +38,12 @@ func (conf *Conf) checkRecord(
 	}
 	conflictingTypes[record.GroupID+"/"+record.Host] = record.Type
 
+	// DNS provider profile record type support
+	unsupported := !conf.profile.supportsType(record.Type)
+	if unsupported {
+		exitVal |= conf.profileEmit(rlog, conf.profile.typeRule(record.Type), nil)
+	}
+
 	// The type specific checks are mostly from the Domain Connect spec
 	switch record.Type {
 	case strCNAME, "NS":
@@ -45,8 +51,8 @@ func (conf *Conf) checkRecord(
 			if template.MultiInstance && record.Type != "NS" {
 				exitVal |= conf.emit(rlog, internal.DCTL1033, nil)
 			}
-			if conf.cloudflare {
-				exitVal |= conf.emit(rlog, internal.DCTL5007, nil)
+			if conf.profile.has(RuleApexCNAME) {
+				exitVal |= conf.profileEmit(rlog, RuleApexCNAME, nil)
 			} else if !template.HostRequired {
 				exitVal |= conf.emit(rlog, internal.DCTL1012, nil)
 			}
@@ -57,6 +63,7 @@ func (conf *Conf) checkRecord(
 			})
 		}
 		exitVal |= targetCheck(conf, record, "pointsTo", rlog)
+		exitVal |= conf.checkTargetName(record, rlog)
 	case "A", "AAAA":
 		if record.Host == "" {
 			exitVal |= conf.emit(rlog, internal.DCTL1013, func(e *zerolog.Event) *zerolog.Event {
@@ -93,14 +100,14 @@ func (conf *Conf) checkRecord(
 			})
 		}
 		exitVal |= targetCheck(conf, record, "data", rlog)
-		if conf.cloudflare {
-			if record.TxtCMM != "" || record.TxtCMM == "None" {
-				exitVal |= conf.emit(rlog, internal.DCTL5008, func(e *zerolog.Event) *zerolog.Event {
+		if conf.profile.has(RuleTxtConflictMatching) {
+			if record.TxtCMM != "" {
+				exitVal |= conf.profileEmit(rlog, RuleTxtConflictMatching, func(e *zerolog.Event) *zerolog.Event {
 					return e.Str("key", "txtConflictMatchingMode")
 				})
 			}
 			if record.TxtCMP != "" {
-				exitVal |= conf.emit(rlog, internal.DCTL5008, func(e *zerolog.Event) *zerolog.Event {
+				exitVal |= conf.profileEmit(rlog, RuleTxtConflictMatching, func(e *zerolog.Event) *zerolog.Event {
 					return e.Str("key", "txtConflictMatchingPrefix")
 				})
 			}
@@ -112,6 +119,8 @@ func (conf *Conf) checkRecord(
 		if strings.Contains(record.Data, "v=spf1") {
 			exitVal |= conf.emit(rlog, internal.DCTL1014, nil)
 		}
+		exitVal |= conf.checkTXTContent(record, rlog)
+		exitVal |= conf.checkDKIMKey(record, rlog)
 
 	case "MX":
 		if record.Host == "" {
@@ -120,6 +129,7 @@ func (conf *Conf) checkRecord(
 			})
 		}
 		exitVal |= targetCheck(conf, record, "pointsTo", rlog)
+		exitVal |= conf.checkTargetName(record, rlog)
 		if priority, ok := record.Priority.Uint32(); !ok || max31b < priority {
 			exitVal |= conf.emit(rlog, internal.DCTL1015, func(e *zerolog.Event) *zerolog.Event {
 				return e.Uint32("priority", priority)
@@ -128,6 +138,7 @@ func (conf *Conf) checkRecord(
 
 	case "SRV":
 		exitVal |= targetCheck(conf, record, "target", rlog)
+		exitVal |= conf.checkTargetName(record, rlog)
 		if isInvalidProtocol(record.Protocol) {
 			proto := record.Protocol
 			exitVal |= conf.emit(rlog, internal.DCTL1015, func(e *zerolog.Event) *zerolog.Event {
@@ -164,9 +175,7 @@ func (conf *Conf) checkRecord(
 		exitVal |= checkSPFRules(conf, strings.ToLower(record.SPFRules), rlog)
 
 	case "APEXCNAME":
-		if conf.cloudflare {
-			exitVal |= conf.emit(rlog, internal.DCTL5009, nil)
-		} else {
+		if !unsupported {
 			exitVal |= conf.emit(rlog, internal.DCTL1038, nil)
 		}
 		if record.PointsTo == "" {
@@ -176,7 +185,9 @@ func (conf *Conf) checkRecord(
 		}
 
 	case "REDIR301", "REDIR302":
-		exitVal |= conf.emit(rlog, internal.DCTL1038, nil)
+		if !unsupported {
+			exitVal |= conf.emit(rlog, internal.DCTL1038, nil)
+		}
 		if record.Target == "" {
 			exitVal |= conf.emit(rlog, internal.DCTL1013, func(e *zerolog.Event) *zerolog.Event {
 				return e.Str("key", "target")
@@ -185,10 +196,27 @@ func (conf *Conf) checkRecord(
 		if record.Host != "" {
 			exitVal |= targetCheck(conf, record, "target", rlog)
 		}
+		if record.Target != "" {
+			exitVal |= conf.checkRedirTarget(record, rlog)
+		}
+
+	case "CAA", "TLSA", "SMIMEA", "SVCB", "HTTPS", "URI", "SSHFP", "DS":
+		if record.Host == "" {
+			exitVal |= conf.emit(rlog, internal.DCTL1013, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("key", "host")
+			})
+		}
+		exitVal |= targetCheck(conf, record, "data", rlog)
+		if record.Data != "" {
+			exitVal |= conf.checkRData(record, rlog)
+		}
 	default:
 		exitVal |= conf.emit(rlog, internal.DCTL1016, nil)
 	}
 
+	// Names must fit DNS limits after variable substitution.
+	exitVal |= conf.checkRecordNames(record, rlog)
+
 	// Check use of underscore host names.
 	exitVal |= conf.checkUnderscoreNames(record.Type, record.Host)
 
@@ -219,8 +247,8 @@ func (conf *Conf) checkRecord(
 		exitVal |= conf.emit(rlog, internal.DCTL1015, func(e *zerolog.Event) *zerolog.Event {
 			return e.Uint32("ttl", ttl)
 		})
-	} else if ok && conf.cloudflare && ttl == 0 {
-		exitVal |= conf.emit(rlog, internal.DCTL5010, nil)
+	} else if ok && !isVariable(string(record.TTL)) {
+		exitVal |= conf.profileTTLChecks(ttl, rlog)
 	}
 	if !ok && ttl == 0 && conf.inplace && 0 < conf.ttl && requiresTTL(record.Type) && isVariable(string(record.TTL)) {
 		rlog.Info().Uint32("ttl", conf.ttl).Msg("adding ttl to the record")
@@ -250,10 +278,8 @@ func (conf *Conf) checkRecord(
 	}
 
 	// DNS provider specific checks
-	if conf.cloudflare {
-		if record.Essential != "" {
-			exitVal |= conf.emit(rlog, internal.DCTL5011, nil)
-		}
+	if record.Essential != "" {
+		exitVal |= conf.profileEmit(rlog, RuleEssential, nil)
 	}
 
 	exitVal |= findInvalidTemplateStrings(conf, record, rlog)
@@ -289,7 +315,8 @@ func isInvalidProtocol(proto string) bool {
 
 func requiresTTL(recordType string) bool {
 	switch recordType {
-	case strCNAME, "NS", "A", "AAAA", "TXT", "MX", "SRV", "APEXCNAME":
+	case strCNAME, "NS", "A", "AAAA", "TXT", "MX", "SRV", "APEXCNAME",
+		"CAA", "TLSA", "SMIMEA", "SVCB", "HTTPS", "URI", "SSHFP", "DS":
 		return true
 	}
 	return false
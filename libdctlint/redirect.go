@@ -0,0 +1,113 @@
+package libdctlint
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// redirPlaceholder replaces service provider variables in a REDIR target
+// so that it can be parsed as an url.
+const redirPlaceholder = "dcvar"
+
+// redirURL substitutes variables in a REDIR target. The built-in domain,
+// fqdn, and host variables are set by the DNS provider and point to the
+// customer domain, so they are replaced with a literal name.
+func redirURL(target string) string {
+	return variableRe.ReplaceAllStringFunc(target, func(v string) string {
+		switch strings.ToLower(strings.Trim(v, "%")) {
+		case "domain", "fqdn":
+			return "example.com"
+		case "host":
+			return "host"
+		}
+		return redirPlaceholder
+	})
+}
+
+// checkRedirTarget validates REDIR301 and REDIR302 target url. Variables
+// are safe in path, query, and fragment. A variable in the registrable part
+// of the host lets the service provider send visitors anywhere.
+func (conf *Conf) checkRedirTarget(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	target := record.Target
+	if checkBareVariables(target) {
+		return conf.emit(rlog, internal.DCTL1072, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("target", target)
+		})
+	}
+
+	u, err := url.Parse(redirURL(target))
+	if err == nil && u.Hostname() == "" {
+		err = fmt.Errorf("url does not have a host")
+	}
+	if err != nil {
+		return conf.emit(rlog, internal.DCTL1069, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("target", target)
+		})
+	}
+
+	exitVal := exitvals.CheckOK
+	switch {
+	case strings.Contains(u.Scheme, redirPlaceholder):
+		exitVal |= conf.emit(rlog, internal.DCTL1009, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("target", target).Str("field", "scheme")
+		})
+	case u.Scheme != "http" && u.Scheme != "https":
+		exitVal |= conf.emit(rlog, internal.DCTL1070, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("scheme", u.Scheme).Str("target", target)
+		})
+	}
+	if u.User != nil {
+		exitVal |= conf.emit(rlog, internal.DCTL1069, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("error", "url must not have user information").Str("target", target)
+		})
+	}
+
+	host := u.Hostname()
+	labels := strings.Split(host, ".")
+	if strings.Contains(strings.Join(labels[max(0, len(labels)-2):], "."), redirPlaceholder) {
+		exitVal |= conf.emit(rlog, internal.DCTL1072, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("target", target)
+		})
+	} else if err := checkFQDN(host); err != nil {
+		exitVal |= conf.emit(rlog, internal.DCTL1071, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("target", target)
+		})
+	}
+	return exitVal
+}
+
+// checkRedirConflicts reports REDIR records that share host and groupId
+// with A, AAAA, or CNAME records. The DNS provider implements redirects
+// with address records of its own web servers.
+func (conf *Conf) checkRedirConflicts(template internal.Template) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+	redirs := make(map[string]bool)
+	for _, record := range template.Records {
+		if record.Type == "REDIR301" || record.Type == "REDIR302" {
+			redirs[record.GroupID+"/"+strings.ToLower(record.Host)] = true
+		}
+	}
+	if len(redirs) == 0 {
+		return exitVal
+	}
+	for rnum, record := range template.Records {
+		switch record.Type {
+		case "A", "AAAA", strCNAME:
+		default:
+			continue
+		}
+		if redirs[record.GroupID+"/"+strings.ToLower(record.Host)] {
+			rlog := conf.tlog.With().Str("groupid", record.GroupID).Int("record", rnum+1).Str("type", record.Type).Logger()
+			exitVal |= conf.emit(rlog, internal.DCTL1073, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("host", record.Host)
+			})
+		}
+	}
+	return exitVal
+}
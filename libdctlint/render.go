@@ -0,0 +1,205 @@
+package libdctlint
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+)
+
+// renderer substitutes variables of one template application.
+type renderer struct {
+	domain string
+	host   string
+	fqdn   string
+	vars   map[string]string
+	err    error
+}
+
+func newRenderer(params ApplyParams) *renderer {
+	r := &renderer{
+		domain: strings.TrimSuffix(params.Domain, "."),
+		host:   params.Host,
+		vars:   make(map[string]string, len(params.Variables)),
+	}
+	r.fqdn = r.domain
+	if r.host != "" {
+		r.fqdn = r.host + "." + r.domain
+	}
+	for name, value := range params.Variables {
+		r.vars[strings.ToLower(name)] = value
+	}
+	return r
+}
+
+// substitute replaces variables in s. The first unknown variable is
+// stored to r.err.
+func (r *renderer) substitute(s string) string {
+	return variableRe.ReplaceAllStringFunc(s, func(match string) string {
+		name := strings.ToLower(match[1 : len(match)-1])
+		switch name {
+		case "domain":
+			return r.domain
+		case "host":
+			return r.host
+		case "fqdn":
+			return r.fqdn
+		}
+		value, ok := r.vars[name]
+		if !ok && r.err == nil {
+			r.err = fmt.Errorf("variable %%%s%% does not have a value", name)
+		}
+		return value
+	})
+}
+
+// name returns owner name relative to the domain.
+func (r *renderer) name(host string) string {
+	host = r.substitute(host)
+	switch {
+	case host == "" || host == "@":
+		if r.host == "" {
+			return "@"
+		}
+		return r.host
+	case r.host == "":
+		return host
+	}
+	return host + "." + r.host
+}
+
+// target returns a record target where @ means the domain+host.
+func (r *renderer) target(s string) string {
+	s = r.substitute(s)
+	if s == "@" {
+		return r.fqdn
+	}
+	return s
+}
+
+func (r *renderer) number(field string, sint internal.SINT) uint32 {
+	s := r.substitute(string(sint))
+	if s == "" {
+		return 0
+	}
+	i, err := strconv.ParseUint(s, 10, 32)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s value '%s' is not a number", field, s)
+	}
+	return uint32(i)
+}
+
+// srvName returns SRV record owner name made of service, protocol, and
+// name fields.
+func (r *renderer) srvName(record internal.Record) string {
+	service := r.substitute(record.Service)
+	if !strings.HasPrefix(service, "_") {
+		service = "_" + service
+	}
+	protocol := r.substitute(record.Protocol)
+	if !strings.HasPrefix(protocol, "_") {
+		protocol = "_" + protocol
+	}
+	host := record.Name
+	if host == "" {
+		host = record.Host
+	}
+	name := r.name(host)
+	if name == "@" {
+		return service + "." + protocol
+	}
+	return service + "." + protocol + "." + name
+}
+
+// resourceRecord renders a single template record. SPFM record data is
+// the substituted spfRules, see RenderTemplate() for merging.
+func (r *renderer) resourceRecord(record internal.Record) internal.ResourceRecord {
+	rr := internal.ResourceRecord{
+		Type: record.Type,
+		Name: r.name(record.Host),
+		TTL:  r.number("ttl", record.TTL),
+	}
+	switch record.Type {
+	case "SPFM":
+		rr.Data = r.substitute(record.SPFRules)
+	case "MX":
+		rr.Data = r.target(record.PointsTo)
+		rr.Priority = r.number("priority", record.Priority)
+	case "SRV":
+		rr.Name = r.srvName(record)
+		rr.Data = r.target(record.Target)
+		rr.Priority = r.number("priority", record.Priority)
+		rr.Weight = r.number("weight", record.Weight)
+		rr.Port = r.number("port", record.Port)
+	case "REDIR301", "REDIR302":
+		rr.Data = r.substitute(record.Target)
+	case "TXT":
+		rr.Data = r.substitute(record.Data)
+	default:
+		if record.PointsTo != "" {
+			rr.Data = r.target(record.PointsTo)
+		} else {
+			rr.Data = r.substitute(record.Data)
+		}
+	}
+	return rr
+}
+
+// RenderTemplate applies the template with the params the way a DNS
+// provider would, and returns the resulting records. Records of groups
+// not in params.GroupIDs are left out, and SPFM records at the same name
+// are merged to a single TXT record.
+func RenderTemplate(template internal.Template, params ApplyParams) ([]internal.ResourceRecord, error) {
+	r := newRenderer(params)
+	var rrs []internal.ResourceRecord
+	spf := make(map[string][]string)
+	spfTTL := make(map[string]uint32)
+	var spfOrder []string
+
+	for _, record := range template.Records {
+		if 0 < len(params.GroupIDs) && !slices.Contains(params.GroupIDs, record.GroupID) {
+			continue
+		}
+		rr := r.resourceRecord(record)
+		if record.Type == "SPFM" {
+			if _, ok := spf[rr.Name]; !ok {
+				spfOrder = append(spfOrder, rr.Name)
+				spfTTL[rr.Name] = rr.TTL
+			}
+			spf[rr.Name] = append(spf[rr.Name], rr.Data)
+			continue
+		}
+		rrs = append(rrs, rr)
+	}
+
+	for _, name := range spfOrder {
+		rrs = append(rrs, internal.ResourceRecord{
+			Type: "TXT",
+			Name: name,
+			Data: "v=spf1 " + strings.Join(spf[name], " ") + " ~all",
+			TTL:  spfTTL[name],
+		})
+	}
+
+	return rrs, r.err
+}
+
+// FormatRecord returns a zone file like presentation of a record.
+func FormatRecord(rr internal.ResourceRecord) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %d %s", rr.Name, rr.TTL, rr.Type)
+	switch rr.Type {
+	case "MX":
+		fmt.Fprintf(&sb, " %d", rr.Priority)
+	case "SRV":
+		fmt.Fprintf(&sb, " %d %d %d", rr.Priority, rr.Weight, rr.Port)
+	}
+	if rr.Type == "TXT" {
+		fmt.Fprintf(&sb, " %q", rr.Data)
+	} else {
+		fmt.Fprintf(&sb, " %s", rr.Data)
+	}
+	return sb.String()
+}
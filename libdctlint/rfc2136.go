@@ -0,0 +1,287 @@
+package libdctlint
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog"
+)
+
+// TXTLookup returns the TXT records that exist at a fully qualified name,
+// each as the character-strings it has in the zone. BuildUpdate() uses it
+// for txtConflictMatchingMode Prefix and SPFM merging, that depend on
+// records already in the zone. The character-strings must be the ones in
+// the zone, because deleting a single record needs an exact match.
+type TXTLookup func(fqdn string) ([][]string, error)
+
+// updateFormat is the format name DCTL2025 messages of BuildUpdate() use.
+const updateFormat = "rfc2136"
+
+// maxTXTString is the longest character-string of a TXT record.
+const maxTXTString = 255
+
+// updateOwner returns the fully qualified owner name of a record name
+// relative to the domain.
+func updateOwner(name, domain string) string {
+	if name == "@" {
+		return dns.Fqdn(domain)
+	}
+	return dns.Fqdn(name + "." + domain)
+}
+
+// txtStrings splits TXT data to character-strings.
+func txtStrings(data string) []string {
+	var ss []string
+	for maxTXTString < len(data) {
+		ss = append(ss, data[:maxTXTString])
+		data = data[maxTXTString:]
+	}
+	return append(ss, data)
+}
+
+// updateRR converts a rendered record to a DNS resource record.
+func updateRR(rr internal.ResourceRecord, domain string) (dns.RR, error) {
+	hdr := dns.RR_Header{
+		Name:   updateOwner(rr.Name, domain),
+		Rrtype: dns.StringToType[rr.Type],
+		Class:  dns.ClassINET,
+		Ttl:    rr.TTL,
+	}
+	switch rr.Type {
+	case "TXT":
+		return &dns.TXT{Hdr: hdr, Txt: txtStrings(rr.Data)}, nil
+	case "MX":
+		return &dns.MX{Hdr: hdr, Preference: uint16(rr.Priority), Mx: dns.Fqdn(rr.Data)}, nil
+	case "SRV":
+		return &dns.SRV{Hdr: hdr, Priority: uint16(rr.Priority), Weight: uint16(rr.Weight),
+			Port: uint16(rr.Port), Target: dns.Fqdn(rr.Data)}, nil
+	case strCNAME:
+		return &dns.CNAME{Hdr: hdr, Target: dns.Fqdn(rr.Data)}, nil
+	case "NS":
+		return &dns.NS{Hdr: hdr, Ns: dns.Fqdn(rr.Data)}, nil
+	}
+	if hdr.Rrtype == 0 {
+		return nil, fmt.Errorf("record type %s is not a DNS type", rr.Type)
+	}
+	return dns.NewRR(hdr.Name + " " + strconv.FormatUint(uint64(rr.TTL), 10) + " IN " + rr.Type + " " + rr.Data)
+}
+
+// rrsetOf returns an update section RR that deletes an RRset, or all
+// RRsets at the name when rtype is ANY.
+func rrsetOf(fqdn string, rtype uint16) dns.RR {
+	return &dns.ANY{Hdr: dns.RR_Header{Name: fqdn, Rrtype: rtype, Class: dns.ClassANY}}
+}
+
+// mergeSPF adds rules to an existing SPF record, keeping its terms first
+// and its all mechanism last. Without an existing record the result is
+// the same as RenderTemplate() SPFM merging.
+func mergeSPF(existing string, rules []string) string {
+	terms := []string{"v=spf1"}
+	all := "~all"
+	fields := strings.Fields(existing)
+	if 0 < len(fields) {
+		// the version term
+		fields = fields[1:]
+	}
+	for _, term := range fields {
+		if strings.HasSuffix(strings.ToLower(term), "all") && len(term) <= 4 {
+			all = term
+			continue
+		}
+		terms = append(terms, term)
+	}
+	for _, rule := range rules {
+		for _, term := range strings.Fields(rule) {
+			if !slices.Contains(terms, term) {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return strings.Join(append(terms, all), " ")
+}
+
+// updateBuilder collects deletions and additions of one DNS UPDATE.
+type updateBuilder struct {
+	domain  string
+	lookup  TXTLookup
+	deletes []dns.RR
+	inserts []dns.RR
+	removed map[string]bool
+	txt     map[string][][]string
+}
+
+// removeRRset deletes a name and type once.
+func (u *updateBuilder) removeRRset(fqdn string, rtype uint16) {
+	key := fqdn + "/" + dns.TypeToString[rtype]
+	if u.removed[key] {
+		return
+	}
+	u.removed[key] = true
+	u.deletes = append(u.deletes, rrsetOf(fqdn, rtype))
+}
+
+// existingTXT returns the TXT records at a name, looked up once.
+func (u *updateBuilder) existingTXT(fqdn string) ([][]string, error) {
+	if data, ok := u.txt[fqdn]; ok {
+		return data, nil
+	}
+	if u.lookup == nil {
+		return nil, nil
+	}
+	data, err := u.lookup(fqdn)
+	if err != nil {
+		return nil, err
+	}
+	u.txt[fqdn] = data
+	return data, nil
+}
+
+// removeTXT deletes a single existing TXT record with the exact
+// character-strings it has in the zone.
+func (u *updateBuilder) removeTXT(fqdn string, txt []string) {
+	key := fqdn + "/TXT/" + strings.Join(txt, "\x00")
+	if u.removed[key] || u.removed[fqdn+"/TXT"] {
+		return
+	}
+	u.removed[key] = true
+	u.deletes = append(u.deletes, &dns.TXT{
+		Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassNONE},
+		Txt: slices.Clone(txt),
+	})
+}
+
+// BuildUpdate applies the template with params, and returns an RFC 2136
+// DNS UPDATE message for zone. Records that conflict with the template
+// are deleted before the template records are added, following the
+// Domain Connect conflict rules. NS records delete all records at the
+// name, but not records below it, because the update cannot tell which
+// names exist. Existing TXT records are queried with lookup when the
+// template has txtConflictMatchingMode Prefix or SPFM records, a nil
+// lookup means there are none. A failed lookup is reported as DCTL2026,
+// and the returned message must not be sent because it does not delete
+// the conflicting records. Record types DNS does not have, such as
+// REDIR301, are reported and left out.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) BuildUpdate(template internal.Template, params ApplyParams, zone string, lookup TXTLookup) (*dns.Msg, exitvals.CheckSeverity) {
+	conf.startCheck()
+	exitVal := conf.checkApplyParams(template, params)
+
+	r := newRenderer(params)
+	u := &updateBuilder{
+		domain:  r.domain,
+		lookup:  lookup,
+		removed: make(map[string]bool),
+		txt:     make(map[string][][]string),
+	}
+	if zone == "" {
+		zone = r.domain
+	}
+	spf := make(map[string][]string)
+	spfTTL := make(map[string]uint32)
+	var spfOrder []string
+
+	for rnum, record := range template.Records {
+		if 0 < len(params.GroupIDs) && !slices.Contains(params.GroupIDs, record.GroupID) {
+			continue
+		}
+		rlog := conf.tlog.With().Str("groupid", record.GroupID).Int("record", rnum+1).Str("type", record.Type).Logger()
+		rr := r.resourceRecord(record)
+		if r.err != nil {
+			break
+		}
+		fqdn := updateOwner(rr.Name, u.domain)
+
+		switch record.Type {
+		case strCNAME, "NS":
+			u.removeRRset(fqdn, dns.TypeANY)
+		case "A", "AAAA":
+			u.removeRRset(fqdn, dns.TypeA)
+			u.removeRRset(fqdn, dns.TypeAAAA)
+		case "MX", "SRV":
+			u.removeRRset(fqdn, dns.StringToType[record.Type])
+		case "TXT":
+			switch record.TxtCMM {
+			case "All":
+				u.removeRRset(fqdn, dns.TypeTXT)
+			case "Prefix":
+				existing, err := u.existingTXT(fqdn)
+				if err != nil {
+					exitVal |= conf.emit(rlog, internal.DCTL2026, func(e *zerolog.Event) *zerolog.Event {
+						return e.Str("name", fqdn).Err(err)
+					})
+				}
+				for _, txt := range existing {
+					if strings.HasPrefix(strings.Join(txt, ""), record.TxtCMP) {
+						u.removeTXT(fqdn, txt)
+					}
+				}
+			}
+		case "SPFM":
+			if _, ok := spf[fqdn]; !ok {
+				spfOrder = append(spfOrder, fqdn)
+				spfTTL[fqdn] = rr.TTL
+			}
+			spf[fqdn] = append(spf[fqdn], rr.Data)
+			continue
+		case "APEXCNAME", "REDIR301", "REDIR302":
+			exitVal |= conf.emit(rlog, internal.DCTL2025, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("format", updateFormat).Str("name", rr.Name)
+			})
+			continue
+		}
+		if record.Type != strCNAME && record.Type != "NS" {
+			u.removeRRset(fqdn, dns.TypeCNAME)
+		}
+
+		drr, err := updateRR(rr, u.domain)
+		if err != nil {
+			exitVal |= conf.emit(rlog, internal.DCTL2025, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("format", updateFormat).Str("name", rr.Name).Err(err)
+			})
+			continue
+		}
+		u.inserts = append(u.inserts, drr)
+	}
+	if r.err != nil {
+		return nil, exitVal | conf.emit(conf.tlog, internal.DCTL2024, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(r.err)
+		})
+	}
+
+	for _, fqdn := range spfOrder {
+		existing, err := u.existingTXT(fqdn)
+		if err != nil {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2026, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("name", fqdn).Err(err)
+			})
+		}
+		var current string
+		for _, txt := range existing {
+			data := strings.Join(txt, "")
+			if isSPF(internal.ResourceRecord{Type: "TXT", Data: data}) {
+				current = data
+				u.removeTXT(fqdn, txt)
+			}
+		}
+		u.removeRRset(fqdn, dns.TypeCNAME)
+		u.inserts = append(u.inserts, &dns.TXT{
+			Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: spfTTL[fqdn]},
+			Txt: txtStrings(mergeSPF(current, spf[fqdn])),
+		})
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zone))
+	// deletions come first, update section is processed in order
+	msg.Ns = append(msg.Ns, u.deletes...)
+	msg.Insert(u.inserts)
+	return msg, exitVal
+}
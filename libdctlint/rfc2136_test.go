@@ -0,0 +1,133 @@
+package libdctlint
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/miekg/dns"
+)
+
+// fakeTXT is a TXTLookup of fixed records.
+func fakeTXT(zone map[string][][]string) TXTLookup {
+	return func(fqdn string) ([][]string, error) {
+		return zone[fqdn], nil
+	}
+}
+
+func TestBuildUpdate(t *testing.T) {
+	existing := map[string][][]string{
+		"example.com.": {
+			{"v=spf1 include:a.example.net", " include:b.example.net ~all"},
+			{"other"},
+		},
+		"_verify.example.com.": {
+			{"token=old", "-part"},
+			{"unrelated"},
+		},
+	}
+	tests := []struct {
+		name    string
+		records []internal.Record
+		deletes []string
+		inserts []string
+	}{
+		{
+			name:    "CNAME",
+			records: []internal.Record{{Type: "CNAME", Host: "www", PointsTo: "target.example.net", TTL: "300"}},
+			deletes: []string{"www.example.com.\t0\tCLASS255\tANY\t"},
+			inserts: []string{"www.example.com.\t300\tIN\tCNAME\ttarget.example.net."},
+		},
+		{
+			name:    "A",
+			records: []internal.Record{{Type: "A", Host: "@", PointsTo: "192.0.2.1", TTL: "300"}},
+			deletes: []string{
+				"example.com.\t0\tCLASS255\tA\t",
+				"example.com.\t0\tCLASS255\tAAAA\t",
+				"example.com.\t0\tCLASS255\tCNAME\t",
+			},
+			inserts: []string{"example.com.\t300\tIN\tA\t192.0.2.1"},
+		},
+		{
+			name: "TXT prefix",
+			records: []internal.Record{{Type: "TXT", Host: "_verify", Data: "token=new", TTL: "300",
+				TxtCMM: "Prefix", TxtCMP: "token="}},
+			deletes: []string{
+				"_verify.example.com.\t0\tNONE\tTXT\t\"token=old\" \"-part\"",
+				"_verify.example.com.\t0\tCLASS255\tCNAME\t",
+			},
+			inserts: []string{"_verify.example.com.\t300\tIN\tTXT\t\"token=new\""},
+		},
+		{
+			name: "SPFM",
+			records: []internal.Record{
+				{Type: "SPFM", Host: "@", SPFRules: "include:c.example.net", TTL: "300"},
+				{Type: "SPFM", Host: "@", SPFRules: "include:a.example.net", TTL: "300"},
+			},
+			deletes: []string{
+				"example.com.\t0\tNONE\tTXT\t\"v=spf1 include:a.example.net\" \" include:b.example.net ~all\"",
+				"example.com.\t0\tCLASS255\tCNAME\t",
+			},
+			inserts: []string{"example.com.\t300\tIN\tTXT\t\"v=spf1 include:a.example.net include:b.example.net include:c.example.net ~all\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf().SetLib(true)
+			template := internal.Template{ProviderID: "example.net", ServiceID: "test", Records: tt.records}
+			msg, exitVal := conf.BuildUpdate(template, ApplyParams{Domain: "example.com"}, "", fakeTXT(existing))
+			if msg == nil || exitvals.CheckWarn <= exitVal {
+				t.Fatalf("exit %v, messages %v", exitVal, conf.GetMessages())
+			}
+			var deletes, inserts []string
+			for _, rr := range msg.Ns {
+				if rr.Header().Class == dns.ClassINET {
+					inserts = append(inserts, rr.String())
+				} else {
+					deletes = append(deletes, rr.String())
+				}
+			}
+			if !slices.Equal(deletes, tt.deletes) {
+				t.Errorf("deletes %q, want %q", deletes, tt.deletes)
+			}
+			if !slices.Equal(inserts, tt.inserts) {
+				t.Errorf("inserts %q, want %q", inserts, tt.inserts)
+			}
+		})
+	}
+}
+
+func TestBuildUpdateLookupError(t *testing.T) {
+	conf := NewConf().SetLib(true)
+	template := internal.Template{ProviderID: "example.net", ServiceID: "test", Records: []internal.Record{
+		{Type: "SPFM", Host: "@", SPFRules: "include:a.example.net", TTL: "300"},
+	}}
+	lookup := func(string) ([][]string, error) { return nil, errors.New("timeout") }
+	_, exitVal := conf.BuildUpdate(template, ApplyParams{Domain: "example.com"}, "", lookup)
+	if exitVal&exitvals.CheckError == 0 {
+		t.Errorf("exit %v, want error", exitVal)
+	}
+	if !slices.ContainsFunc(conf.GetMessages(), func(m DCTLMessage) bool { return m.Code == internal.DCTL2026 }) {
+		t.Errorf("no DCTL2026 in %v", conf.GetMessages())
+	}
+}
+
+func TestMergeSPF(t *testing.T) {
+	tests := []struct {
+		existing string
+		rules    []string
+		want     string
+	}{
+		{"", []string{"include:a"}, "v=spf1 include:a ~all"},
+		{"v=spf1 mx -all", []string{"include:a", "mx"}, "v=spf1 mx include:a -all"},
+		{"v=spf1 include:a ?all", []string{"include:b include:a"}, "v=spf1 include:a include:b ?all"},
+	}
+	for _, tt := range tests {
+		if got := mergeSPF(tt.existing, tt.rules); got != tt.want {
+			t.Errorf("mergeSPF(%q, %q) = %q, want %q", tt.existing, tt.rules, got, tt.want)
+		}
+	}
+}
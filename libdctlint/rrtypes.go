@@ -0,0 +1,396 @@
+package libdctlint
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// rdataCodes are the DCTL codes of record types that keep presentation
+// format RDATA in the record data field.
+var rdataCodes = map[string]internal.DCTL{
+	"CAA":    internal.DCTL1041,
+	"TLSA":   internal.DCTL1042,
+	"SMIMEA": internal.DCTL1042,
+	"SVCB":   internal.DCTL1043,
+	"HTTPS":  internal.DCTL1043,
+	"URI":    internal.DCTL1044,
+	"SSHFP":  internal.DCTL1045,
+	"DS":     internal.DCTL1046,
+}
+
+// rdataVariableError tells a variable is used in a field that must be
+// a literal value.
+type rdataVariableError struct {
+	field string
+}
+
+func (e *rdataVariableError) Error() string {
+	return "variable in " + e.field
+}
+
+// splitRData splits presentation format RDATA to fields. Quoted strings
+// are single fields with the quotes removed.
+func splitRData(s string) ([]string, error) {
+	var fields []string
+	var sb strings.Builder
+	inField, quoted, escaped := false, false, false
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+			inField = true
+		case c == '"':
+			quoted = !quoted
+			inField = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inField {
+				fields = append(fields, sb.String())
+				sb.Reset()
+				inField = false
+			}
+		default:
+			sb.WriteRune(c)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quoted string")
+	}
+	if inField {
+		fields = append(fields, sb.String())
+	}
+	return fields, nil
+}
+
+// rdataNumber checks a literal unsigned integer field is within bounds.
+func rdataNumber(field, value string, maxValue uint64, variableOK bool) error {
+	if isVariable(value) {
+		if variableOK {
+			return nil
+		}
+		return &rdataVariableError{field: field}
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || maxValue < n {
+		return fmt.Errorf("%s '%s' is not a number between 0 and %d", field, value, maxValue)
+	}
+	return nil
+}
+
+// rdataHex checks a hex field, variables are allowed. Zero hexLen accepts
+// any length.
+func rdataHex(field, value string, hexLen int) error {
+	if isVariable(value) {
+		return nil
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return fmt.Errorf("%s is not hex: %w", field, err)
+	}
+	if 0 < hexLen && len(value) != hexLen {
+		return fmt.Errorf("%s length is %d, expected %d hex characters", field, len(value), hexLen)
+	}
+	return nil
+}
+
+func rdataFieldCount(fields []string, count int, format string) error {
+	if len(fields) != count {
+		return fmt.Errorf("expected format '%s', found %d fields", format, len(fields))
+	}
+	return nil
+}
+
+var caaTags = map[string]bool{
+	"contactemail": true,
+	"contactphone": true,
+	"iodef":        true,
+	"issue":        true,
+	"issuemail":    true,
+	"issuevmc":     true,
+	"issuewild":    true,
+}
+
+func checkCAA(fields []string) []error {
+	if err := rdataFieldCount(fields, 3, "flags tag value"); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	if err := rdataNumber("flags", fields[0], 255, false); err != nil {
+		errs = append(errs, err)
+	} else if fields[0] != "0" && fields[0] != "128" {
+		errs = append(errs, fmt.Errorf("flags '%s' should be 0 or 128", fields[0]))
+	}
+
+	tag := fields[1]
+	value := fields[2]
+	switch {
+	case isVariable(tag):
+		errs = append(errs, &rdataVariableError{field: "tag"})
+	case !caaTags[strings.ToLower(tag)]:
+		errs = append(errs, fmt.Errorf("unknown tag '%s'", tag))
+	case isVariable(value):
+		// variables are ok
+	case strings.EqualFold(tag, "iodef"):
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("iodef '%s' must be mailto, http, or https url", value))
+		}
+	case strings.HasPrefix(strings.ToLower(tag), "issue"):
+		issuer, _, _ := strings.Cut(value, ";")
+		issuer = strings.TrimSpace(issuer)
+		if issuer != "" && !strings.Contains(issuer, "%") && checkFQDN(issuer) != nil {
+			errs = append(errs, fmt.Errorf("%s issuer '%s' is not a domain name", tag, issuer))
+		}
+	}
+	return errs
+}
+
+func checkTLSA(fields []string) []error {
+	if len(fields) < 4 {
+		return []error{errors.New("expected format 'usage selector matching-type data'")}
+	}
+	var errs []error
+	for i, f := range []struct {
+		name string
+		max  uint64
+	}{
+		{"usage", 3},
+		{"selector", 1},
+		{"matching-type", 2},
+	} {
+		if err := rdataNumber(f.name, fields[i], f.max, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	hexLen := 0
+	switch fields[2] {
+	case "1":
+		hexLen = 64
+	case "2":
+		hexLen = 128
+	}
+	// data can be split to several fields
+	if err := rdataHex("data", strings.Join(fields[3:], ""), hexLen); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func checkSVCB(fields []string) []error {
+	if len(fields) < 2 {
+		return []error{errors.New("expected format 'priority target [params...]'")}
+	}
+	var errs []error
+	if err := rdataNumber("priority", fields[0], max16b, false); err != nil {
+		errs = append(errs, err)
+	}
+	target := fields[1]
+	if target != "." && !isVariable(target) && checkFQDN(strings.TrimSuffix(target, ".")) != nil {
+		errs = append(errs, fmt.Errorf("target '%s' is not a domain name", target))
+	}
+
+	params := fields[2:]
+	if fields[0] == "0" && 0 < len(params) {
+		errs = append(errs, errors.New("alias mode, priority 0, must not have params"))
+	}
+	seen := make(map[string]bool)
+	for _, param := range params {
+		key, value, hasValue := strings.Cut(param, "=")
+		key = strings.ToLower(key)
+		if isVariable(key) {
+			errs = append(errs, &rdataVariableError{field: "param key"})
+			continue
+		}
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("duplicate param '%s'", key))
+		}
+		seen[key] = true
+		if isVariable(value) {
+			continue
+		}
+		if err := checkSvcParam(key, value, hasValue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func checkSvcParam(key, value string, hasValue bool) error {
+	switch key {
+	case "no-default-alpn":
+		if hasValue {
+			return errors.New("no-default-alpn must not have a value")
+		}
+		return nil
+	case "mandatory", "alpn", "ech":
+		if value == "" {
+			return fmt.Errorf("%s must have a value", key)
+		}
+	case "port":
+		return rdataNumber("port", value, max16b, true)
+	case "ipv4hint", "ipv6hint":
+		for addr := range strings.SplitSeq(value, ",") {
+			ip := net.ParseIP(addr)
+			if ip == nil || (key == "ipv4hint") != (ip.To4() != nil) {
+				return fmt.Errorf("%s '%s' is not a valid address", key, addr)
+			}
+		}
+	default:
+		num, ok := strings.CutPrefix(key, "key")
+		if !ok {
+			return fmt.Errorf("unknown param '%s'", key)
+		}
+		if err := rdataNumber("param key", num, max16b, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkURI(fields []string) []error {
+	if err := rdataFieldCount(fields, 3, "priority weight \"target\""); err != nil {
+		return []error{err}
+	}
+	var errs []error
+	if err := rdataNumber("priority", fields[0], max16b, false); err != nil {
+		errs = append(errs, err)
+	}
+	if err := rdataNumber("weight", fields[1], max16b, false); err != nil {
+		errs = append(errs, err)
+	}
+	target := fields[2]
+	if !isVariable(target) {
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme == "" {
+			errs = append(errs, fmt.Errorf("target '%s' is not an absolute uri", target))
+		}
+	}
+	return errs
+}
+
+func checkSSHFP(fields []string) []error {
+	if len(fields) < 3 {
+		return []error{errors.New("expected format 'algorithm fingerprint-type fingerprint'")}
+	}
+	var errs []error
+	if err := rdataNumber("algorithm", fields[0], 255, false); err != nil {
+		errs = append(errs, err)
+	} else {
+		switch fields[0] {
+		case "1", "2", "3", "4", "6":
+		default:
+			errs = append(errs, fmt.Errorf("unknown algorithm '%s'", fields[0]))
+		}
+	}
+	hexLen := 0
+	switch fields[1] {
+	case "1":
+		hexLen = 40
+	case "2":
+		hexLen = 64
+	default:
+		if err := rdataNumber("fingerprint-type", fields[1], 255, false); err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, fmt.Errorf("unknown fingerprint-type '%s'", fields[1]))
+		}
+	}
+	// fingerprint can be split to several fields
+	if err := rdataHex("fingerprint", strings.Join(fields[2:], ""), hexLen); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func checkDS(fields []string) []error {
+	if len(fields) < 4 {
+		return []error{errors.New("expected format 'key-tag algorithm digest-type digest'")}
+	}
+	var errs []error
+	if err := rdataNumber("key-tag", fields[0], max16b, true); err != nil {
+		errs = append(errs, err)
+	}
+	if err := rdataNumber("algorithm", fields[1], 255, false); err != nil {
+		errs = append(errs, err)
+	}
+	hexLen := 0
+	switch fields[2] {
+	case "1":
+		hexLen = 40
+	case "2":
+		hexLen = 64
+	case "4":
+		hexLen = 96
+	default:
+		if err := rdataNumber("digest-type", fields[2], 255, false); err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, fmt.Errorf("unknown digest-type '%s'", fields[2]))
+		}
+	}
+	// digest can be split to several fields
+	if err := rdataHex("digest", strings.Join(fields[3:], ""), hexLen); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// checkRData validates the record data field of record types that use
+// presentation format RDATA.
+func (conf *Conf) checkRData(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	if checkBareVariables(record.Data) {
+		rlog.Debug().Str("data", record.Data).Msg("record data is a variable, skipping rdata checks")
+		return exitvals.CheckOK
+	}
+	dctl := rdataCodes[record.Type]
+	fields, err := splitRData(record.Data)
+	if err != nil {
+		return conf.emit(rlog, dctl, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("data", record.Data)
+		})
+	}
+
+	var errs []error
+	switch record.Type {
+	case "CAA":
+		errs = checkCAA(fields)
+	case "TLSA", "SMIMEA":
+		errs = checkTLSA(fields)
+	case "SVCB", "HTTPS":
+		errs = checkSVCB(fields)
+	case "URI":
+		errs = checkURI(fields)
+	case "SSHFP":
+		errs = checkSSHFP(fields)
+	case "DS":
+		errs = checkDS(fields)
+	}
+
+	exitVal := exitvals.CheckOK
+	for _, err := range errs {
+		var verr *rdataVariableError
+		if errors.As(err, &verr) {
+			exitVal |= conf.emit(rlog, internal.DCTL1009, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("field", verr.field).Str("data", record.Data)
+			})
+			continue
+		}
+		exitVal |= conf.emit(rlog, dctl, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("data", record.Data)
+		})
+	}
+	return exitVal
+}
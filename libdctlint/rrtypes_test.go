@@ -0,0 +1,81 @@
+package libdctlint
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+)
+
+var (
+	sha1Hex   = strings.Repeat("0123456789", 4)
+	sha256Hex = strings.Repeat("0123456789abcdef", 4)
+)
+
+func TestCheckRData(t *testing.T) {
+	tests := []struct {
+		recordType string
+		data       string
+		want       []internal.DCTL
+	}{
+		{"CAA", `0 issue "letsencrypt.org"`, nil},
+		{"CAA", `0 iodef "mailto:security@example.com"`, nil},
+		{"CAA", `0 issue "%ca%"`, nil},
+		{"CAA", `0 issue`, []internal.DCTL{internal.DCTL1041}},
+		{"CAA", `1 issue "letsencrypt.org"`, []internal.DCTL{internal.DCTL1041}},
+		{"CAA", `0 foo "bar"`, []internal.DCTL{internal.DCTL1041}},
+		{"CAA", `0 iodef "ftp://example.com"`, []internal.DCTL{internal.DCTL1041}},
+		{"CAA", `0 %tag% "letsencrypt.org"`, []internal.DCTL{internal.DCTL1009}},
+
+		{"TLSA", "3 1 1 " + sha256Hex, nil},
+		{"TLSA", "3 1 1 " + sha256Hex[:32] + " " + sha256Hex[32:], nil},
+		{"SMIMEA", "3 0 0 0123abcd", nil},
+		{"TLSA", "3 1 1", []internal.DCTL{internal.DCTL1042}},
+		{"TLSA", "4 1 1 " + sha256Hex, []internal.DCTL{internal.DCTL1042}},
+		{"TLSA", "3 1 1 " + sha256Hex[:32], []internal.DCTL{internal.DCTL1042}},
+		{"TLSA", "3 1 1 0123 xyz", []internal.DCTL{internal.DCTL1042}},
+
+		{"SVCB", "1 svc.example.com. alpn=h2", nil},
+		{"HTTPS", "0 svc.example.com.", nil},
+		{"HTTPS", "1 .", nil},
+		{"SVCB", "1", []internal.DCTL{internal.DCTL1043}},
+		{"SVCB", "x svc.example.com.", []internal.DCTL{internal.DCTL1043}},
+		{"HTTPS", "1 -invalid-", []internal.DCTL{internal.DCTL1043}},
+
+		{"URI", `10 1 "https://example.com/"`, nil},
+		{"URI", `10 1`, []internal.DCTL{internal.DCTL1044}},
+		{"URI", `10 x "https://example.com/"`, []internal.DCTL{internal.DCTL1044}},
+
+		{"SSHFP", "4 2 " + sha256Hex, nil},
+		{"SSHFP", "1 1 " + sha1Hex, nil},
+		{"SSHFP", "4 2 " + sha256Hex[:32] + " " + sha256Hex[32:], nil},
+		{"SSHFP", "4 2", []internal.DCTL{internal.DCTL1045}},
+		{"SSHFP", "5 2 " + sha256Hex, []internal.DCTL{internal.DCTL1045}},
+		{"SSHFP", "4 2 " + sha1Hex, []internal.DCTL{internal.DCTL1045}},
+		{"SSHFP", "4 2 " + sha256Hex[:32] + " xyz", []internal.DCTL{internal.DCTL1045}},
+
+		{"DS", "12345 13 2 " + sha256Hex, nil},
+		{"DS", "12345 13 2 " + sha256Hex[:32] + " " + sha256Hex[32:], nil},
+		{"DS", "12345 13 2", []internal.DCTL{internal.DCTL1046}},
+		{"DS", "12345 13 2 " + sha1Hex, []internal.DCTL{internal.DCTL1046}},
+		{"DS", "70000 13 2 " + sha256Hex, []internal.DCTL{internal.DCTL1046}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recordType+" "+tt.data, func(t *testing.T) {
+			conf := NewConf().SetLib(true)
+			conf.startCheck()
+			record := internal.Record{Type: tt.recordType, Host: "@", Data: tt.data}
+			conf.checkRData(&record, conf.tlog)
+			var got []internal.DCTL
+			for _, msg := range conf.GetMessages() {
+				if !slices.Contains(got, msg.Code) {
+					got = append(got, msg.Code)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v, messages %v", got, tt.want, conf.GetMessages())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package libdctlint
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+)
+
+// newValidator returns a validator with Domain Connect specific tags
+// registered. The dcfqdn tag checks field is a domain name, and dcurlhost
+// checks url host part is a domain name, both by using checkFQDN().
+func newValidator() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	_ = validate.RegisterValidation("dcfqdn", func(fl validator.FieldLevel) bool {
+		return checkFQDN(strings.TrimSuffix(fl.Field().String(), ".")) == nil
+	})
+	_ = validate.RegisterValidation("dcurlhost", func(fl validator.FieldLevel) bool {
+		u, err := url.Parse(fl.Field().String())
+		if err != nil || u.Hostname() == "" {
+			return false
+		}
+		return checkFQDN(u.Hostname()) == nil
+	})
+	return validate
+}
+
+// CheckSettings takes bufio.Reader as an argument and will check DNS
+// provider settings json document. Please remember to set conf.fileName
+// appropriately before calling this function to avoid confusing results.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and accessible
+// via GetMessages() after this call returns.
+func (conf *Conf) CheckSettings(f *bufio.Reader) exitvals.CheckSeverity {
+	conf.startCheck()
+	conf.tlog.Debug().Msg("starting settings check")
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	var settings internal.Settings
+	err := decoder.Decode(&settings)
+	if err != nil {
+		conf.emit(conf.tlog, internal.DCTL0003, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+		return exitvals.CheckFatal
+	}
+
+	exitVal := exitvals.CheckOK
+
+	// Check 'validate:' fields in internal/json.go definitions
+	err = newValidator().Struct(settings)
+	if err != nil {
+		for _, verr := range err.(validator.ValidationErrors) {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL4000, func(e *zerolog.Event) *zerolog.Event {
+				return e.Err(verr)
+			})
+		}
+	}
+
+	if checkInvalidChars(settings.ProviderID) {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL4001, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("providerId", settings.ProviderID)
+		})
+	}
+
+	for _, u := range []struct {
+		key   string
+		value string
+	}{
+		{"urlSyncUX", settings.URLSyncUX},
+		{"urlAsyncUX", settings.URLAsyncUX},
+		{"urlAPI", settings.URLAPI},
+		{"urlControlPanel", settings.URLControlPanel},
+	} {
+		if u.value != "" && !strings.HasPrefix(strings.ToLower(u.value), "https://") {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL4002, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str(u.key, u.value)
+			})
+		}
+	}
+
+	if settings.URLSyncUX != "" && (settings.Width == 0 || settings.Height == 0) {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL4003, func(e *zerolog.Event) *zerolog.Event {
+			return e.Uint("width", settings.Width).Uint("height", settings.Height)
+		})
+	}
+
+	if settings.URLControlPanel != "" && !strings.Contains(settings.URLControlPanel, "%domain%") {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL4004, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("urlControlPanel", settings.URLControlPanel)
+		})
+	}
+
+	conf.tlog.Debug().Uint32("exitVal", uint32(exitVal)).Msg("settings check done")
+	return exitVal
+}
+
+// CheckDiscovery takes bufio.Reader as an argument and will check the
+// content of a _domainconnect TXT record. The record holds the host, and
+// optional path, of the DNS provider urlAPI without url scheme.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and accessible
+// via GetMessages() after this call returns.
+func (conf *Conf) CheckDiscovery(f *bufio.Reader) exitvals.CheckSeverity {
+	conf.startCheck()
+	conf.tlog.Debug().Msg("starting discovery record check")
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		conf.emit(conf.tlog, internal.DCTL0001, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+		return exitvals.CheckFatal
+	}
+
+	var records []string
+	for line := range strings.SplitSeq(string(content), "\n") {
+		if line = unquoteTXT(strings.TrimSpace(line)); line != "" {
+			records = append(records, line)
+		}
+	}
+
+	exitVal := exitvals.CheckOK
+	switch len(records) {
+	case 0:
+		return conf.emit(conf.tlog, internal.DCTL4005, nil)
+	case 1:
+	default:
+		exitVal |= conf.emit(conf.tlog, internal.DCTL4008, func(e *zerolog.Event) *zerolog.Event {
+			return e.Strs("records", records)
+		})
+	}
+
+	for _, record := range records {
+		if strings.Contains(record, "://") {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL4006, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("record", record)
+			})
+			_, record, _ = strings.Cut(record, "://")
+		}
+		host, _, _ := strings.Cut(record, "/")
+		if err := checkFQDN(host); err != nil || host == "" {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL4007, func(e *zerolog.Event) *zerolog.Event {
+				return e.AnErr("error", err).Str("record", record)
+			})
+		}
+	}
+
+	conf.tlog.Debug().Uint32("exitVal", uint32(exitVal)).Msg("discovery record check done")
+	return exitVal
+}
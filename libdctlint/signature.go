@@ -0,0 +1,284 @@
+package libdctlint
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// applyQuery is a raw apply query string split to the part that is
+// signed, and the sig and key parameters that are not.
+type applyQuery struct {
+	signed    string
+	sig       string
+	key       string
+	sigLast   bool
+	hasSigKey bool
+}
+
+// splitApplyQuery separates sig and key parameters from the rest of the
+// query string. The signed part is kept byte for byte as it was in the
+// input, because that is what the service provider signed. Full apply
+// URLs are accepted, everything before '?' is ignored.
+func splitApplyQuery(query string) (applyQuery, error) {
+	aq := applyQuery{sigLast: true}
+	if i := strings.IndexByte(query, '?'); -1 < i {
+		query = query[i+1:]
+	}
+	query = strings.TrimPrefix(query, "&")
+
+	var signed []string
+	for param := range strings.SplitSeq(query, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		switch name {
+		case "sig", "key":
+			// a DNS provider base64 decodes sig as it is, so an
+			// unencoded '+' must not turn to a space
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return aq, fmt.Errorf("%s: %w", name, err)
+			}
+			if name == "sig" {
+				aq.sig = unescaped
+			} else {
+				aq.key = unescaped
+			}
+			aq.hasSigKey = true
+		default:
+			if aq.hasSigKey {
+				aq.sigLast = false
+			}
+			signed = append(signed, param)
+		}
+	}
+	aq.signed = strings.Join(signed, "&")
+
+	if _, err := url.ParseQuery(aq.signed); err != nil {
+		return aq, err
+	}
+	return aq, nil
+}
+
+// ParsePublicKey parses a Domain Connect signing public key. The input can
+// be PEM encoded, or the content of the TXT records published at
+// <key>.<syncPubKeyDomain>, one record per line, for example
+// "p=1,a=RS256,d=MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA...".
+func ParsePublicKey(b []byte) (*rsa.PublicKey, error) {
+	var der []byte
+	if block, _ := pem.Decode(b); block != nil {
+		switch block.Type {
+		case "PUBLIC KEY", "RSA PUBLIC KEY":
+			der = block.Bytes
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return asRSAPublicKey(cert.PublicKey)
+		default:
+			return nil, fmt.Errorf("unexpected pem block type '%s'", block.Type)
+		}
+	} else {
+		data, err := joinKeyRecords(string(b))
+		if err != nil {
+			return nil, err
+		}
+		der, err = base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("public key is not base64: %w", err)
+		}
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return asRSAPublicKey(key)
+	}
+	key, err := x509.ParsePKCS1PublicKey(der)
+	if err != nil {
+		return nil, errors.New("not a PKIX or PKCS #1 public key")
+	}
+	return key, nil
+}
+
+func asRSAPublicKey(key any) (*rsa.PublicKey, error) {
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errNotRSA, key)
+	}
+	return rsaKey, nil
+}
+
+var errNotRSA = errors.New("public key is not RSA")
+
+// joinKeyRecords concatenates the d= parts of public key TXT records in
+// the p= order. Lines without p= and d= are taken as bare base64 data.
+func joinKeyRecords(content string) (string, error) {
+	type part struct {
+		index int
+		data  string
+	}
+	var parts []part
+	var bare strings.Builder
+
+	for line := range strings.SplitSeq(content, "\n") {
+		line = unquoteTXT(strings.TrimSpace(line))
+		if line == "" {
+			continue
+		}
+		if !strings.Contains(line, "d=") {
+			bare.WriteString(internal.StripSpaces(line))
+			continue
+		}
+		p := part{index: -1}
+		for field := range strings.SplitSeq(line, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(field), "=")
+			switch k {
+			case "p":
+				i, err := strconv.Atoi(v)
+				if err != nil {
+					return "", fmt.Errorf("invalid p= index '%s'", v)
+				}
+				p.index = i
+			case "a":
+				if v != "RS256" {
+					return "", fmt.Errorf("unsupported algorithm a=%s", v)
+				}
+			case "d":
+				p.data = internal.StripSpaces(v)
+			}
+		}
+		if p.index < 0 {
+			p.index = len(parts)
+		}
+		parts = append(parts, p)
+	}
+
+	if len(parts) == 0 {
+		if bare.Len() == 0 {
+			return "", errors.New("no key data found")
+		}
+		return bare.String(), nil
+	}
+	slices.SortStableFunc(parts, func(a, b part) int {
+		return a.index - b.index
+	})
+	var sb strings.Builder
+	for i, p := range parts {
+		if 0 < i && p.index == parts[i-1].index {
+			return "", fmt.Errorf("duplicate p=%d record", p.index)
+		}
+		sb.WriteString(p.data)
+	}
+	return sb.String(), nil
+}
+
+// unquoteTXT removes zone file style quoting from TXT record content.
+// Multiple quoted strings are concatenated.
+func unquoteTXT(s string) string {
+	if !strings.HasPrefix(s, "\"") {
+		return s
+	}
+	var sb strings.Builder
+	for i, str := range strings.Split(s, "\"") {
+		if i%2 == 1 {
+			sb.WriteString(str)
+		}
+	}
+	return sb.String()
+}
+
+// VerifyApplySignature checks a synchronous apply request signature the
+// way a DNS provider does. The query is an apply query string or a full
+// apply URL. When sig or key is empty the value is taken from the query.
+// pubKey is either a PEM encoded public key, or the TXT record content
+// published at <key>.<syncPubKeyDomain>.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) VerifyApplySignature(template internal.Template, query, sig, key string, pubKey []byte) exitvals.CheckSeverity {
+	conf.startCheck()
+	exitVal := exitvals.CheckOK
+
+	if template.SyncPubKeyDomain == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2000, nil)
+	}
+
+	aq, err := splitApplyQuery(query)
+	if err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2001, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+	if !aq.sigLast {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2008, nil)
+	}
+	if sig == "" {
+		sig = aq.sig
+	}
+	if key == "" {
+		key = aq.key
+	}
+
+	return exitVal | conf.verifySignature(template, aq.signed, sig, key, pubKey)
+}
+
+// verifySignature checks sig is a valid signature of the signed query
+// string made with the private half of pubKey.
+func (conf *Conf) verifySignature(template internal.Template, signed, sig, key string, pubKey []byte) exitvals.CheckSeverity {
+	exitVal := exitvals.CheckOK
+
+	if key == "" {
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2003, nil)
+	} else if template.SyncPubKeyDomain != "" {
+		conf.tlog.Debug().Str("record", key+"."+template.SyncPubKeyDomain).Msg("public key location")
+	}
+
+	if sig == "" {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2002, nil)
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2004, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	rsaKey, err := ParsePublicKey(pubKey)
+	if err != nil {
+		dctl := internal.DCTL2005
+		if errors.Is(err, errNotRSA) {
+			dctl = internal.DCTL2006
+		}
+		return exitVal | conf.emit(conf.tlog, dctl, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	conf.tlog.Debug().Str("signed", signed).Msg("verifying signature")
+	digest := sha256.Sum256([]byte(signed))
+	err = rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], sigBytes)
+	if err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2007, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err).Str("signed", signed).Int("keybits", rsaKey.N.BitLen())
+		})
+	}
+
+	conf.tlog.Info().Str("key", key).Msg("signature verified")
+	return exitVal
+}
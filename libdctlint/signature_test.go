@@ -0,0 +1,54 @@
+package libdctlint
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+)
+
+// The key pair and signature are made with openssl, independent of this
+// package:
+//
+//	openssl genrsa -out priv.pem 2048
+//	openssl rsa -in priv.pem -pubout
+//	printf '%s' "$signed" | openssl dgst -sha256 -sign priv.pem | base64 -w0
+const (
+	katPublicKey = `-----BEGIN PUBLIC KEY-----
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAlPH7lH4NXpK7oTAYLUfC
+gWMMSXmQe9+hGL4Cx8nxuAcBlYUzpuagrMA9Jkl7U7H07M+ifryibF87mQIpA90e
+5AeUFhWMReMl4FTVuDZB6cNIi5IcmICbyUK461lgU7cMYTyXRpFeAYHDnZ1qjhLj
+h6qvttY+sdsHUQNIH5dhgjwVtwA99zLCulOn9FIuBcG4N0azUJeasWePdB00I8bx
+V/ZNfN459q/pUR0zUB8+bkpx5VxjrsITa0f3J+xxQvuXcIP3M1KRMilHHfZfPdz6
+/7yDNWygbqpEHJtmm5HO2K6Upo7ngyFVBLM+JQXvUXUKQM29cc2OO5SYq5WV1QOZ
+MwIDAQAB
+-----END PUBLIC KEY-----
+`
+	katSigned    = "domain=example.com&IP=192.0.2.1&RANDOMTEXT=shm%3A1234%3Ahello"
+	katSignature = "VsLrGSysCj8uJiyHxUfyZZNSwlw0SIGMwQwU8IeRBjpWXbrviOTL2XKWayiWhFNd4FPv3mMmQBzxys6bXPB8QmkPje90KO4yTy7v5fpKQCVd3ZwDj7n7mp+HHO9ZDpkfoJS8Jk6zDfq6BfZU/0n+rSCH2XXXTWIvg6XmdyHWxq073isg2yLLaT+N5YuO538+2tCaFaWsVfLtiL6wdAFJDc85G41d+tRN0UhmH2cLA0T2P5dyiEpsFrOpOu38horYobS2ctpW4DIbzNa/xVdREdPkV/ltzLsat1qIPlX5GGMpL5H/O2fGm8vsdRX2zsvycnXpsoUiulVIVrB3CIwzpA=="
+)
+
+func TestVerifyApplySignatureKnownAnswer(t *testing.T) {
+	template := internal.Template{ProviderID: "example.com", ServiceID: "kat", SyncPubKeyDomain: "example.com"}
+	tests := []struct {
+		name  string
+		query string
+		want  exitvals.CheckSeverity
+	}{
+		{"encoded sig", katSigned + "&sig=" + url.QueryEscape(katSignature) + "&key=_dck1", exitvals.CheckOK},
+		{"raw sig", katSigned + "&sig=" + katSignature + "&key=_dck1", exitvals.CheckOK},
+		{"full url", "https://connect.example.net/v2/domainTemplates/providers/example.com/services/kat/apply?" +
+			katSigned + "&sig=" + katSignature + "&key=_dck1", exitvals.CheckOK},
+		{"modified query", "domain=example.com&IP=192.0.2.2&RANDOMTEXT=shm%3A1234%3Ahello&sig=" + katSignature + "&key=_dck1", exitvals.CheckError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := NewConf().SetLib(true)
+			got := conf.VerifyApplySignature(template, tt.query, "", "", []byte(katPublicKey))
+			if got != tt.want {
+				t.Errorf("got %v, want %v, messages %v", got, tt.want, conf.GetMessages())
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package libdctlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SummaryCatalog holds the messages of a consent summary language.
+// Messages are keyed by summary sentence kind, and {placeholder} words in
+// them are replaced with template values. Keys missing from a catalog
+// fall back to English.
+type SummaryCatalog struct {
+	Language string            `json:"language" yaml:"language"`
+	Messages map[string]string `json:"messages" yaml:"messages"`
+}
+
+var englishSummary = SummaryCatalog{
+	Language: "en",
+	Messages: map[string]string{
+		"title":          "{service} by {provider} will:",
+		"apex":           "your domain",
+		"host":           "the chosen subdomain",
+		"subname":        "{name} under {host}",
+		"variable":       "<{name}>",
+		"group":          "{group}: {sentence}",
+		"points":         "Point {name} to {target}",
+		"mx":             "Route email for {name} to {target} with priority {priority}",
+		"spf-include":    "Add an SPF include for {value} to {name}",
+		"spf":            "Add SPF rules {value} to {name}",
+		"txt":            "Add a TXT record {value} to {name}",
+		"txt-replace":    "Replace all TXT records of {name} with {value}",
+		"txt-prefix":     "Replace TXT records of {name} starting with {prefix} with {value}",
+		"ns":             "Delegate {name} to nameservers {target}",
+		"srv":            "Publish the {service} service over {protocol} for {name} at {target} port {port}",
+		"redirect":       "Redirect {name} to {target}",
+		"record":         "Add a {type} record {value} to {name}",
+		"controlled":     "Values in angle brackets are set by the service provider: {value}",
+		"list-separator": ", ",
+	},
+}
+
+var germanSummary = SummaryCatalog{
+	Language: "de",
+	Messages: map[string]string{
+		"title":       "{service} von {provider} wird:",
+		"apex":        "Ihre Domain",
+		"host":        "die gewählte Subdomain",
+		"subname":     "{name} unterhalb der gewählten Subdomain",
+		"points":      "{name} auf {target} verweisen",
+		"mx":          "E-Mails für {name} mit Priorität {priority} an {target} leiten",
+		"spf-include": "Einen Include für {value} in den SPF-Eintrag für {name} aufnehmen",
+		"spf":         "SPF-Regeln {value} in den SPF-Eintrag für {name} aufnehmen",
+		"txt":         "Einen TXT-Eintrag {value} für {name} hinzufügen",
+		"txt-replace": "Alle TXT-Einträge für {name} durch {value} ersetzen",
+		"txt-prefix":  "TXT-Einträge für {name}, die mit {prefix} beginnen, durch {value} ersetzen",
+		"ns":          "{name} an die Nameserver {target} delegieren",
+		"srv":         "Den Dienst {service} über {protocol} für {name} auf {target} Port {port} veröffentlichen",
+		"redirect":    "{name} auf {target} weiterleiten",
+		"record":      "Einen {type}-Eintrag {value} für {name} hinzufügen",
+		"controlled":  "Werte in spitzen Klammern legt der Diensteanbieter fest: {value}",
+	},
+}
+
+var summaryCatalogs = map[string]*SummaryCatalog{
+	englishSummary.Language: &englishSummary,
+	germanSummary.Language:  &germanSummary,
+}
+
+// RegisterSummaryCatalog adds a message catalog to the registry. Catalog
+// languages must be unique.
+func RegisterSummaryCatalog(c *SummaryCatalog) error {
+	if c.Language == "" {
+		return errors.New("catalog language must not be empty")
+	}
+	if _, found := summaryCatalogs[c.Language]; found {
+		return fmt.Errorf("summary catalog '%s' is already registered", c.Language)
+	}
+	summaryCatalogs[c.Language] = c
+	return nil
+}
+
+// LookupSummaryCatalog returns a registered message catalog.
+func LookupSummaryCatalog(language string) (*SummaryCatalog, bool) {
+	c, ok := summaryCatalogs[language]
+	return c, ok
+}
+
+// SummaryLanguages returns registered catalog languages in sorted order.
+func SummaryLanguages() []string {
+	return sortedKeys(summaryCatalogs)
+}
+
+// LoadSummaryCatalogFile reads a message catalog from a json or yaml
+// file. Files with .yaml or .yml suffix are read as yaml, others as json.
+// The returned catalog is not registered, see RegisterSummaryCatalog().
+func LoadSummaryCatalogFile(fileName string) (*SummaryCatalog, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var c SummaryCatalog
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&c)
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	for key := range c.Messages {
+		if _, ok := englishSummary.Messages[key]; !ok {
+			return nil, fmt.Errorf("%s: unknown message '%s'", fileName, key)
+		}
+	}
+	return &c, nil
+}
+
+// summarizer renders summary sentences of one template.
+type summarizer struct {
+	catalog      *SummaryCatalog
+	hostRequired bool
+	controlled   []string
+}
+
+// message returns a catalog message with placeholders replaced. The
+// args are placeholder name and value pairs.
+func (s *summarizer) message(key string, args ...string) string {
+	msg, ok := s.catalog.Messages[key]
+	if !ok {
+		msg = englishSummary.Messages[key]
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// value marks variables in s, and remembers the ones the service
+// provider sets. The built-in variables are described the same way as
+// record names, because the end user chooses them.
+func (s *summarizer) value(v string) string {
+	return variableRe.ReplaceAllStringFunc(v, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch strings.ToLower(name) {
+		case "domain":
+			return s.message("apex")
+		case "host":
+			return s.message("host")
+		case "fqdn":
+			return s.name("@")
+		}
+		if !slices.Contains(s.controlled, name) {
+			s.controlled = append(s.controlled, name)
+		}
+		return s.message("variable", "name", name)
+	})
+}
+
+// name returns the name a record is added to. With hostRequired the
+// names are below the chosen subdomain.
+func (s *summarizer) name(host string) string {
+	if host == "" || host == "@" {
+		if s.hostRequired {
+			return s.message("host")
+		}
+		return s.message("apex")
+	}
+	if s.hostRequired {
+		return s.message("subname", "name", s.value(host), "host", s.message("host"))
+	}
+	return s.value(host)
+}
+
+// target returns a pointsTo or target value, where @ is the domain.
+func (s *summarizer) target(t string) string {
+	if t == "@" {
+		return s.name(t)
+	}
+	return s.value(t)
+}
+
+// sentence explains a single record.
+func (s *summarizer) sentence(record internal.Record) string {
+	name := s.name(record.Host)
+	switch record.Type {
+	case "A", "AAAA", strCNAME, "APEXCNAME":
+		return s.message("points", "name", name, "target", s.target(record.PointsTo))
+	case "MX":
+		return s.message("mx", "name", name, "target", s.target(record.PointsTo), "priority", s.value(string(record.Priority)))
+	case "SPFM":
+		var includes, rules []string
+		for _, term := range strings.Fields(record.SPFRules) {
+			if include, ok := strings.CutPrefix(term, "include:"); ok {
+				includes = append(includes, s.value(include))
+			} else {
+				rules = append(rules, s.value(term))
+			}
+		}
+		var sentences []string
+		if 0 < len(includes) {
+			sentences = append(sentences, s.message("spf-include", "name", name, "value", s.list(includes)))
+		}
+		if 0 < len(rules) {
+			sentences = append(sentences, s.message("spf", "name", name, "value", strings.Join(rules, " ")))
+		}
+		return strings.Join(sentences, "; ")
+	case "TXT":
+		value := s.value(record.Data)
+		switch record.TxtCMM {
+		case "All":
+			return s.message("txt-replace", "name", name, "value", value)
+		case "Prefix":
+			return s.message("txt-prefix", "name", name, "value", value, "prefix", s.value(record.TxtCMP))
+		}
+		return s.message("txt", "name", name, "value", value)
+	case "SRV":
+		host := record.Name
+		if host == "" {
+			host = record.Host
+		}
+		return s.message("srv", "name", s.name(host), "target", s.target(record.Target),
+			"service", s.value(strings.TrimPrefix(record.Service, "_")),
+			"protocol", s.value(strings.TrimPrefix(record.Protocol, "_")),
+			"port", s.value(string(record.Port)))
+	case "REDIR301", "REDIR302":
+		return s.message("redirect", "name", name, "target", s.value(record.Target))
+	}
+	value := record.Data
+	if value == "" {
+		value = record.PointsTo
+	}
+	return s.message("record", "type", record.Type, "name", name, "value", s.value(value))
+}
+
+func (s *summarizer) list(values []string) string {
+	return strings.Join(values, s.message("list-separator"))
+}
+
+// Summarize explains in plain language what applying the template does,
+// to be shown to the end user when asking for consent. The first line is
+// a title, the rest are one sentence per record, except that NS records
+// of the same name are one delegation. The last line lists variables the
+// service provider sets. A nil catalog means English.
+func Summarize(template internal.Template, catalog *SummaryCatalog) []string {
+	if catalog == nil {
+		catalog = &englishSummary
+	}
+	s := &summarizer{catalog: catalog, hostRequired: template.HostRequired}
+	lines := []string{s.message("title", "service", template.ServiceName, "provider", template.ProviderName)}
+
+	// NS records of the same name are a single delegation, reported at
+	// the position of the first one
+	nameservers := make(map[string][]string)
+	delegationKey := func(record internal.Record) string {
+		return record.GroupID + "/" + strings.ToLower(record.Host)
+	}
+	for _, record := range template.Records {
+		if record.Type == "NS" {
+			key := delegationKey(record)
+			nameservers[key] = append(nameservers[key], s.target(record.PointsTo))
+		}
+	}
+
+	for _, record := range template.Records {
+		var sentence string
+		if record.Type == "NS" {
+			key := delegationKey(record)
+			if nameservers[key] == nil {
+				continue
+			}
+			sentence = s.message("ns", "name", s.name(record.Host), "target", s.list(nameservers[key]))
+			nameservers[key] = nil
+		} else {
+			sentence = s.sentence(record)
+		}
+		if record.GroupID != "" {
+			sentence = s.message("group", "group", record.GroupID, "sentence", sentence)
+		}
+		lines = append(lines, sentence)
+	}
+
+	if 0 < len(s.controlled) {
+		lines = append(lines, s.message("controlled", "value", s.list(s.controlled)))
+	}
+	return lines
+}
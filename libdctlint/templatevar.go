@@ -2,6 +2,7 @@ package libdctlint
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Domain-Connect/dc-template-linter/exitvals"
@@ -98,3 +99,61 @@ func trailingVariable(conf *Conf, host string, rnum int) {
 		}
 	}
 }
+
+// builtinVariables are provided by the DNS provider, they are never part
+// of an apply request.
+var builtinVariables = map[string]bool{
+	"domain": true,
+	"fqdn":   true,
+	"host":   true,
+}
+
+var variableRe = regexp.MustCompile(`%([-0-9A-Za-z_]+)%`)
+
+// recordStrings returns record fields that may contain variables.
+func recordStrings(record internal.Record) []string {
+	return []string{
+		record.Host,
+		record.Name,
+		record.PointsTo,
+		record.Data,
+		record.Service,
+		record.Protocol,
+		record.Target,
+		record.SPFRules,
+		string(record.TTL),
+		string(record.Priority),
+		string(record.Weight),
+		string(record.Port),
+	}
+}
+
+// recordVariables returns lowercased names of the non-builtin variables
+// a record uses.
+func recordVariables(record internal.Record) []string {
+	var vars []string
+	for _, s := range recordStrings(record) {
+		for _, m := range variableRe.FindAllStringSubmatch(s, -1) {
+			name := strings.ToLower(m[1])
+			if !builtinVariables[name] {
+				vars = append(vars, name)
+			}
+		}
+	}
+	return vars
+}
+
+// templateVariables returns the set of non-builtin variables that records
+// of the selected groups need. An empty groupIDs selects all records.
+func templateVariables(template internal.Template, groupIDs []string) map[string]struct{} {
+	vars := make(map[string]struct{})
+	for _, record := range template.Records {
+		if 0 < len(groupIDs) && !slices.Contains(groupIDs, record.GroupID) {
+			continue
+		}
+		for _, name := range recordVariables(record) {
+			vars[name] = struct{}{}
+		}
+	}
+	return vars
+}
@@ -0,0 +1,151 @@
+package libdctlint
+
+import (
+	"bufio"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+)
+
+// TemplateTestFile returns the companion test file name of a template,
+// that is <providerId>.<serviceId>.test.json in the template directory.
+func TemplateTestFile(template internal.Template, templatePath string) string {
+	return filepath.Join(filepath.Dir(templatePath),
+		strings.ToLower(template.ProviderID)+"."+strings.ToLower(template.ServiceID)+".test.json")
+}
+
+// rrKey identifies a record for comparison, names are case-insensitive.
+func rrKey(rr internal.ResourceRecord) string {
+	rr.Name = strings.ToLower(strings.TrimSuffix(rr.Name, "."))
+	rr.Type = strings.ToUpper(rr.Type)
+	return FormatRecord(rr)
+}
+
+// diffRecords returns records that are expected but missing, and the
+// records that were not expected.
+func diffRecords(expected, got []internal.ResourceRecord) (missing, unexpected []internal.ResourceRecord) {
+	count := make(map[string]int)
+	for _, rr := range got {
+		count[rrKey(rr)]++
+	}
+	for _, rr := range expected {
+		key := rrKey(rr)
+		if count[key] == 0 {
+			missing = append(missing, rr)
+			continue
+		}
+		count[key]--
+	}
+	for _, rr := range got {
+		key := rrKey(rr)
+		if 0 < count[key] {
+			unexpected = append(unexpected, rr)
+			count[key]--
+		}
+	}
+	return missing, unexpected
+}
+
+// RunTemplateTests reads test cases from f, applies the template with the
+// inputs of each test, and compares the result with the expected records.
+// Please remember to set conf.fileName to the template file name before
+// calling this function.
+//
+// In library mode (SetLib(true)) all DCTL messages are stored and
+// accessible via GetMessages() after this call returns.
+func (conf *Conf) RunTemplateTests(template internal.Template, f *bufio.Reader) exitvals.CheckSeverity {
+	conf.startCheck()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	var tests internal.TemplateTests
+	err := decoder.Decode(&tests)
+	if err != nil {
+		conf.emit(conf.tlog, internal.DCTL0003, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+		return exitvals.CheckFatal
+	}
+
+	exitVal := exitvals.CheckOK
+	err = newValidator().Struct(tests)
+	if err != nil {
+		for _, verr := range err.(validator.ValidationErrors) {
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2201, func(e *zerolog.Event) *zerolog.Event {
+				return e.Err(verr)
+			})
+		}
+		return exitVal
+	}
+
+	tlog := conf.tlog
+	for _, test := range tests.Tests {
+		conf.tlog = tlog.With().Str("test", test.Name).Logger()
+		exitVal |= conf.runTemplateTest(template, test)
+	}
+	conf.tlog = tlog
+
+	return exitVal
+}
+
+func (conf *Conf) runTemplateTest(template internal.Template, test internal.TemplateTest) exitvals.CheckSeverity {
+	conf.tlog.Debug().Msg("running test")
+	params := ApplyParams{
+		Domain:    test.Domain,
+		Host:      test.Host,
+		GroupIDs:  test.GroupIDs,
+		Variables: test.Variables,
+	}
+	exitVal := conf.checkApplyParams(template, params)
+
+	got, err := RenderTemplate(template, params)
+	if err != nil {
+		return exitVal | conf.emit(conf.tlog, internal.DCTL2202, func(e *zerolog.Event) *zerolog.Event {
+			return e.Err(err)
+		})
+	}
+
+	missing, unexpected := diffRecords(test.Records, got)
+
+	// Pair records with the same type and name as a difference
+	for i := 0; i < len(missing); i++ {
+		for j := range unexpected {
+			if !strings.EqualFold(missing[i].Type, unexpected[j].Type) ||
+				!strings.EqualFold(missing[i].Name, unexpected[j].Name) {
+				continue
+			}
+			expected := FormatRecord(missing[i])
+			result := FormatRecord(unexpected[j])
+			exitVal |= conf.emit(conf.tlog, internal.DCTL2205, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("expected", expected).Str("got", result)
+			})
+			missing = append(missing[:i], missing[i+1:]...)
+			unexpected = append(unexpected[:j], unexpected[j+1:]...)
+			i--
+			break
+		}
+	}
+	for _, rr := range missing {
+		expected := FormatRecord(rr)
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2203, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("expected", expected)
+		})
+	}
+	for _, rr := range unexpected {
+		result := FormatRecord(rr)
+		exitVal |= conf.emit(conf.tlog, internal.DCTL2204, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("got", result)
+		})
+	}
+
+	if exitVal == exitvals.CheckOK {
+		conf.tlog.Info().Msg("test passed")
+	}
+	return exitVal
+}
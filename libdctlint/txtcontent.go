@@ -0,0 +1,337 @@
+package libdctlint
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+// txtTag is a single tag=value pair of a TXT record tag list.
+type txtTag struct {
+	name  string
+	value string
+}
+
+// txtFormat describes a tag list based TXT record format, such as DMARC
+// policy or DKIM key record.
+type txtFormat struct {
+	name string
+	// version is the required value of the v= tag, that must be the
+	// first tag when present
+	version         string
+	versionRequired bool
+	required        []string
+	// tags are the known tags and their value checks, nil check accepts
+	// any value
+	tags    map[string]func(string) error
+	invalid internal.DCTL
+	missing internal.DCTL
+}
+
+var errTagValue = errors.New("invalid value")
+
+func oneOf(values ...string) func(string) error {
+	return func(s string) error {
+		for _, v := range values {
+			if strings.EqualFold(s, v) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w '%s', expected one of %s", errTagValue, s, strings.Join(values, ", "))
+	}
+}
+
+func uintRange(minValue, maxValue uint64) func(string) error {
+	return func(s string) error {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || n < minValue || maxValue < n {
+			return fmt.Errorf("%w '%s', expected number between %d and %d", errTagValue, s, minValue, maxValue)
+		}
+		return nil
+	}
+}
+
+// listOf applies check to each sep separated item of a value.
+func listOf(sep string, check func(string) error) func(string) error {
+	return func(s string) error {
+		for item := range strings.SplitSeq(s, sep) {
+			item = strings.TrimSpace(item)
+			if isVariable(item) {
+				continue
+			}
+			if err := check(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// uriWithScheme accepts absolute URIs of the listed schemes.
+func uriWithScheme(schemes ...string) func(string) error {
+	return func(s string) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errTagValue, err)
+		}
+		if err := oneOf(schemes...)(u.Scheme); err != nil {
+			return fmt.Errorf("%w '%s', scheme must be one of %s", errTagValue, s, strings.Join(schemes, ", "))
+		}
+		if u.Opaque == "" && u.Host == "" {
+			return fmt.Errorf("%w '%s', uri does not have a target", errTagValue, s)
+		}
+		return nil
+	}
+}
+
+var dmarcSizeRe = regexp.MustCompile(`^[0-9]+[kKmMgGtT]?$`)
+
+// dmarcURI accepts a DMARC report uri with optional !size limit.
+func dmarcURI(s string) error {
+	uri, size, found := strings.Cut(s, "!")
+	if found && !dmarcSizeRe.MatchString(size) {
+		return fmt.Errorf("%w '%s', invalid size limit", errTagValue, s)
+	}
+	return uriWithScheme("mailto", "https")(uri)
+}
+
+// base64Data removes whitespace and quotes that split base64 data, such
+// as a DKIM key broken to several quoted strings. Both are reported as
+// DCTL1061 by checkDKIMKey().
+func base64Data(s string) string {
+	return internal.StripSpaces(strings.ReplaceAll(s, "\"", ""))
+}
+
+func base64Value(s string) error {
+	if _, err := base64.StdEncoding.DecodeString(base64Data(s)); err != nil {
+		return fmt.Errorf("%w, not base64: %w", errTagValue, err)
+	}
+	return nil
+}
+
+// optionalHTTPS accepts empty value or an https url.
+func optionalHTTPS(s string) error {
+	if s == "" {
+		return nil
+	}
+	return uriWithScheme("https")(s)
+}
+
+var mtaSTSIDRe = regexp.MustCompile(`^[0-9A-Za-z]{1,32}$`)
+
+var txtFormats = map[string]*txtFormat{
+	"dmarc": {
+		name:            "DMARC",
+		version:         "DMARC1",
+		versionRequired: true,
+		required:        []string{"p"},
+		tags: map[string]func(string) error{
+			"p":     oneOf("none", "quarantine", "reject"),
+			"sp":    oneOf("none", "quarantine", "reject"),
+			"np":    oneOf("none", "quarantine", "reject"),
+			"adkim": oneOf("r", "s"),
+			"aspf":  oneOf("r", "s"),
+			"pct":   uintRange(0, 100),
+			"fo":    listOf(":", oneOf("0", "1", "d", "s")),
+			"rf":    listOf(":", oneOf("afrf")),
+			"ri":    uintRange(0, 1<<32-1),
+			"rua":   listOf(",", dmarcURI),
+			"ruf":   listOf(",", dmarcURI),
+			"psd":   oneOf("y", "n", "u"),
+			"t":     oneOf("y", "n"),
+		},
+		invalid: internal.DCTL1047,
+		missing: internal.DCTL1048,
+	},
+	"dkim": {
+		name:     "DKIM",
+		version:  "DKIM1",
+		required: []string{"p"},
+		tags: map[string]func(string) error{
+			"k": oneOf("rsa", "ed25519"),
+			"p": func(s string) error {
+				// empty p= means revoked key
+				if s == "" {
+					return nil
+				}
+				return base64Value(s)
+			},
+			"h": listOf(":", oneOf("sha1", "sha256")),
+			"s": listOf(":", oneOf("*", "email")),
+			"t": listOf(":", oneOf("y", "s")),
+			"n": nil,
+		},
+		invalid: internal.DCTL1049,
+		missing: internal.DCTL1050,
+	},
+	"mta-sts": {
+		name:            "MTA-STS",
+		version:         "STSv1",
+		versionRequired: true,
+		required:        []string{"id"},
+		tags: map[string]func(string) error{
+			"id": func(s string) error {
+				if !mtaSTSIDRe.MatchString(s) {
+					return fmt.Errorf("%w '%s', expected 1 to 32 alphanumeric characters", errTagValue, s)
+				}
+				return nil
+			},
+		},
+		invalid: internal.DCTL1051,
+		missing: internal.DCTL1052,
+	},
+	"tls-rpt": {
+		name:            "TLS-RPT",
+		version:         "TLSRPTv1",
+		versionRequired: true,
+		required:        []string{"rua"},
+		tags: map[string]func(string) error{
+			"rua": listOf(",", uriWithScheme("mailto", "https")),
+		},
+		invalid: internal.DCTL1053,
+		missing: internal.DCTL1054,
+	},
+	"bimi": {
+		name:            "BIMI",
+		version:         "BIMI1",
+		versionRequired: true,
+		required:        []string{"l"},
+		tags: map[string]func(string) error{
+			"l": optionalHTTPS,
+			"a": optionalHTTPS,
+		},
+		invalid: internal.DCTL1055,
+		missing: internal.DCTL1056,
+	},
+}
+
+// txtFormatFor selects TXT content format by the record host labels.
+func txtFormatFor(host string) *txtFormat {
+	labels := strings.Split(strings.ToLower(host), ".")
+	switch {
+	case labels[0] == "_dmarc":
+		return txtFormats["dmarc"]
+	case labels[0] == "_mta-sts":
+		return txtFormats["mta-sts"]
+	case labels[0] == "_smtp" && 1 < len(labels) && labels[1] == "_tls":
+		return txtFormats["tls-rpt"]
+	case 1 < len(labels) && labels[1] == "_domainkey":
+		return txtFormats["dkim"]
+	case 1 < len(labels) && labels[1] == "_bimi":
+		return txtFormats["bimi"]
+	}
+	return nil
+}
+
+var tagNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// parseTagList splits a tag=value; list. Trailing semicolon is allowed.
+func parseTagList(data string) ([]txtTag, error) {
+	var tags []txtTag
+	specs := strings.Split(data, ";")
+	for i, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			if i == len(specs)-1 {
+				break
+			}
+			return tags, errors.New("empty tag")
+		}
+		name, value, found := strings.Cut(spec, "=")
+		if !found {
+			return tags, fmt.Errorf("tag '%s' does not have a value", spec)
+		}
+		tags = append(tags, txtTag{
+			name:  strings.TrimSpace(name),
+			value: strings.TrimSpace(value),
+		})
+	}
+	return tags, nil
+}
+
+// checkTXTContent validates email authentication and reporting TXT records.
+func (conf *Conf) checkTXTContent(record *internal.Record, rlog zerolog.Logger) exitvals.CheckSeverity {
+	format := txtFormatFor(record.Host)
+	if format == nil || record.Data == "" {
+		return exitvals.CheckOK
+	}
+	if checkBareVariables(record.Data) {
+		rlog.Debug().Str("data", record.Data).Msg("record data is a variable, skipping content checks")
+		return exitvals.CheckOK
+	}
+	exitVal := exitvals.CheckOK
+	invalid := func(tag string, err error) {
+		exitVal |= conf.emit(rlog, format.invalid, func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("format", format.name).Str("tag", tag).Err(err).Str("data", record.Data)
+		})
+	}
+
+	tags, err := parseTagList(record.Data)
+	if err != nil {
+		invalid("", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, tag := range tags {
+		if isVariable(tag.name) {
+			name := tag.name
+			exitVal |= conf.emit(rlog, internal.DCTL1009, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("tag", name)
+			})
+			continue
+		}
+		if !tagNameRe.MatchString(tag.name) {
+			invalid(tag.name, errors.New("invalid tag name"))
+			continue
+		}
+		if seen[tag.name] {
+			invalid(tag.name, errors.New("duplicate tag"))
+		}
+		seen[tag.name] = true
+
+		if tag.name == "v" {
+			if i != 0 {
+				invalid(tag.name, errors.New("version must be the first tag"))
+			} else if tag.value != format.version {
+				invalid(tag.name, fmt.Errorf("%w '%s', expected %s", errTagValue, tag.value, format.version))
+			}
+			continue
+		}
+		check, known := format.tags[tag.name]
+		if !known {
+			name := tag.name
+			exitVal |= conf.emit(rlog, internal.DCTL1057, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("format", format.name).Str("tag", name)
+			})
+			continue
+		}
+		if check == nil || isVariable(tag.value) {
+			continue
+		}
+		if err := check(tag.value); err != nil {
+			invalid(tag.name, err)
+		}
+	}
+
+	required := format.required
+	if format.versionRequired {
+		required = append([]string{"v"}, required...)
+	}
+	for _, name := range required {
+		if !seen[name] {
+			exitVal |= conf.emit(rlog, format.missing, func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("format", format.name).Str("tag", name)
+			})
+		}
+	}
+	return exitVal
+}
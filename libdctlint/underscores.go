@@ -16,12 +16,15 @@ const (
 	TypeNULL       uint16 = 10
 	TypeTXT        uint16 = 16
 	TypeSRV        uint16 = 33
+	TypeDS         uint16 = 43
+	TypeSSHFP      uint16 = 44
 	TypeTLSA       uint16 = 52
 	TypeSMIMEA     uint16 = 53
 	TypeOPENPGPKEY uint16 = 61
 	TypeSVCB       uint16 = 64
 	TypeHTTPS      uint16 = 65
 	TypeURI        uint16 = 256
+	TypeCAA        uint16 = 257
 )
 
 var recordToType = map[string]uint16{
@@ -30,17 +33,21 @@ var recordToType = map[string]uint16{
 	"NULL":       TypeNULL,
 	"TXT":        TypeTXT,
 	"SRV":        TypeSRV,
+	"DS":         TypeDS,
+	"SSHFP":      TypeSSHFP,
 	"TLSA":       TypeTLSA,
 	"SMIMEA":     TypeSMIMEA,
 	"OPENPGPKEY": TypeOPENPGPKEY,
 	"SVCB":       TypeSVCB,
 	"HTTPS":      TypeHTTPS,
 	"URI":        TypeURI,
+	"CAA":        TypeCAA,
 }
 
 var rfc8552 = map[string][]uint16{
 	"_acct":                    {TypeURI},
 	"_acme-challenge":          {TypeTXT},
+	"_bimi":                    {TypeTXT},
 	"_dane":                    {TypeTLSA},
 	"_dccp":                    {TypeSRV, TypeURI},
 	"_dmarc":                   {TypeTXT},
@@ -69,11 +76,13 @@ var rfc8552 = map[string][]uint16{
 	"_sctp":                    {TypeSRV, TypeTLSA, TypeURI},
 	"_sip":                     {TypeSRV, TypeURI},
 	"_smimecert":               {TypeSMIMEA},
+	"_smtp":                    {TypeTXT},
 	"_sms":                     {TypeURI},
 	"_spf":                     {TypeTXT},
 	"_sztp":                    {TypeTXT},
 	"_ta-*":                    {TypeNULL},
 	"_tcp":                     {TypeTXT, TypeSRV, TypeTLSA, TypeURI},
+	"_tls":                     {TypeTXT},
 	"_udp":                     {TypeTXT, TypeSRV, TypeTLSA, TypeURI},
 	"_unifmsg":                 {TypeURI},
 	"_validation-contactemail": {TypeTXT},
@@ -105,6 +114,9 @@ func (conf *Conf) checkUnderscoreNames(rrtype, host string) exitvals.CheckSeveri
 		if len(elem) == 0 || elem[0] != '_' {
 			continue
 		}
+		if isPortLabel(elem) && portLabelTypes[strings.ToUpper(rrtype)] {
+			continue
+		}
 		okTypes, ok := rfc8552[strings.ToLower(elem)]
 		if !ok {
 			elem := elem
@@ -147,3 +159,23 @@ func isStaticLabelUnderscore(elem string) bool {
 	}
 	return false
 }
+
+// portLabelTypes are record types that are prefixed with _<port> label,
+// such as _443._tcp for TLSA and _8443._https for SVCB and HTTPS.
+var portLabelTypes = map[string]bool{
+	"HTTPS": true,
+	"SVCB":  true,
+	"TLSA":  true,
+}
+
+func isPortLabel(elem string) bool {
+	if len(elem) < 2 {
+		return false
+	}
+	for _, c := range elem[1:] {
+		if c < '0' || '9' < c {
+			return false
+		}
+	}
+	return true
+}
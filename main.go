@@ -14,6 +14,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Domain-Connect/dc-template-linter/exitvals"
@@ -25,24 +27,38 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func getRuntimeConf() *libdctlint.Conf {
+// runMode decides what is done to each input document, and optionally
+// what is done after all inputs are processed.
+type runMode struct {
+	check  func(*bufio.Reader) exitvals.CheckSeverity
+	finish func() exitvals.CheckSeverity
+}
+
+func getRuntimeConf() (*libdctlint.Conf, runMode) {
 	// Command line option handling
 	flag.Usage = func() {
 		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s [options] <template.json> [...]\n", os.Args[0])
+		_, _ = fmt.Fprintf(os.Stderr, "   or: %s <command> [options] [...]\n", os.Args[0])
+		_, _ = fmt.Fprintf(os.Stderr, "Commands: %s\n", strings.Join(commandNames(), " "))
 		flag.PrintDefaults()
 		_, _ = fmt.Fprintf(os.Stderr, "Warning. -inplace and -pretty will remove zero priority MX and SRV fields\n")
 		_, _ = fmt.Fprintf(os.Stderr, "You can find long DCTL explanations in wiki\n")
 		_, _ = fmt.Fprintf(os.Stderr, "e.g., https://github.com/Domain-Connect/dc-template-linter/wiki/DCTL1003\n")
 	}
-	checkLogos := flag.Bool("logos", false, "check logo urls are reachable (requires network)")
-	cloudflare := flag.Bool("cloudflare", false, "use Cloudflare specific template rules")
-	mergeOrFail := flag.Bool("merge-or-fail", false, "the https://github.com/Domain-Connect/Templates auto-merge condition")
+	lint := addLintFlags(flag.CommandLine)
+	catalogFile := flag.String("catalog", "", "write a json catalog of checked templates to the file")
+	catalogPrev := flag.String("catalog-prev", "", "previous -catalog file to report added and removed services against")
 	inplace := flag.Bool("inplace", false, "inplace write back pretty-print")
 	indent := flag.Uint("indent", 4, "number of spaces in an indent step of the pretty json")
 	increment := flag.Bool("increment", false, "increment template version, useful when pretty-printing")
+	kind := flag.String("kind", "template", "input document kind: template settings discovery")
+	compat := flag.Bool("compat", false, "report template compatibility with all DNS provider profiles")
+	conflicts := flag.Bool("conflicts", false, "report conflicts between templates applied to the same domain")
+	summary := flag.Bool("summary", false, "write a plain language consent summary of templates")
+	summaryLang := flag.String("summary-lang", "en", "-summary language: "+strings.Join(libdctlint.SummaryLanguages(), " "))
+	summaryFile := flag.String("summary-file", "", "load -summary message catalog from json or yaml file, and use it unless -summary-lang is set")
+	format := flag.String("format", "markdown", "-compat and -conflicts report format: markdown json")
 	prettyPrint := flag.Bool("pretty", false, "pretty-print template json")
-	loglevel := flag.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
-	toleration := flag.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
 	ttl := flag.Uint("ttl", 0, "-inplace ttl fix value to be used when template ttl is zero or invalid")
 	version := flag.Bool("version", false, "output version information and exit")
 	flag.Parse()
@@ -53,18 +69,7 @@ func getRuntimeConf() *libdctlint.Conf {
 		os.Exit(0)
 	}
 
-	// Override loglevel and other options when --merge-or-fail is in use
-	if *mergeOrFail {
-		t := true
-		checkLogos = &t
-		tol := "debug"
-		toleration = &tol
-		ll := "info"
-		loglevel = &ll
-	}
-
-	// Runtime init
-	internal.SetLoglevel(*loglevel)
+	conf := lint.conf()
 
 	log.Debug().Uint("version", internal.ProjectVersion).Msg("dc-template-linter version")
 
@@ -76,21 +81,103 @@ func getRuntimeConf() *libdctlint.Conf {
 		log.Fatal().Uint("indent", *indent).Msg("too large indent")
 	}
 
-	conf := libdctlint.NewConf().
-		SetCheckLogos(*checkLogos).
-		SetCloudflare(*cloudflare).
-		SetIncrement(*increment).
+	summaryLangSet := false
+	flag.Visit(func(f *flag.Flag) {
+		summaryLangSet = summaryLangSet || f.Name == "summary-lang"
+	})
+	if *summaryFile != "" {
+		c, err := libdctlint.LoadSummaryCatalogFile(*summaryFile)
+		if err == nil {
+			err = libdctlint.RegisterSummaryCatalog(c)
+		}
+		if err != nil {
+			log.Fatal().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		}
+		if !summaryLangSet {
+			*summaryLang = c.Language
+		}
+	}
+	catalog, ok := libdctlint.LookupSummaryCatalog(*summaryLang)
+	if !ok {
+		log.Fatal().Str("language", *summaryLang).Strs("known", libdctlint.SummaryLanguages()).Msg("unknown summary language")
+	}
+
+	conf.SetIncrement(*increment).
 		SetIndent(*indent).
 		SetInplace(*inplace).
-		SetMergeOrFail(*mergeOrFail).
 		SetPrettyPrint(*prettyPrint).
-		SetToleration(*toleration).
 		SetTTL(uint32(*ttl))
 
-	return conf
+	// Report modes replace template checks, so they cannot be combined
+	// with each other or with options that need the checks
+	reports := 0
+	for _, report := range []bool{*compat, *conflicts, *summary} {
+		if report {
+			reports++
+		}
+	}
+	if 1 < reports {
+		log.Fatal().Msg("-compat, -conflicts, and -summary cannot be combined")
+	}
+	if 0 < reports && (*catalogFile != "" || *kind != "template") {
+		log.Fatal().Msg("-compat, -conflicts, and -summary work only with -kind template and without -catalog")
+	}
+
+	var mode runMode
+	switch *kind {
+	case "template":
+		mode.check = conf.CheckTemplate
+		mode.finish = conf.CheckProviders
+	case "settings":
+		mode.check = conf.CheckSettings
+	case "discovery":
+		mode.check = conf.CheckDiscovery
+	default:
+		log.Fatal().Str("kind", *kind).Msg("unknown input kind")
+	}
+
+	if *catalogFile != "" {
+		mode.finish = catalogFinish(conf, *catalogFile, *catalogPrev, mode.finish)
+	}
+
+	if *compat {
+		mode = newCompatMode(conf, *format)
+	}
+	if *conflicts {
+		mode = newConflictsMode(conf, *format)
+	}
+	if *summary {
+		mode = newSummaryMode(conf, catalog)
+	}
+
+	return conf, mode
 }
 
-func main() {
+// command is a subcommand entry point. The args do not include the
+// program and command names.
+type command func(args []string) exitvals.CheckSeverity
+
+var commands = map[string]command{
+	"apply-rfc2136": applyRFC2136Command,
+	"apply-url":     applyURLCommand,
+	"check-apply":   checkApplyCommand,
+	"export":        exportCommand,
+	"report":        reportCommand,
+	"import":        importCommand,
+	"test":          testCommand,
+	"verify-sig":    verifySigCommand,
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func initLogging() {
 	// Init logging. Essentially colors or no colors?
 	if isatty.IsTerminal(os.Stderr.Fd()) {
 		log.Logger = log.Output(
@@ -102,15 +189,61 @@ func main() {
 	} else {
 		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	}
+}
+
+// tolerate masks exitVal bits that are below toleration threshold.
+func tolerate(toleration zerolog.Level, exitVal exitvals.CheckSeverity) exitvals.CheckSeverity {
+	switch toleration {
+	case zerolog.Disabled:
+		exitVal = exitvals.CheckOK
+	case zerolog.ErrorLevel:
+		exitVal &= exitvals.CheckFatal
+	case zerolog.WarnLevel:
+		exitVal &= exitvals.CheckFatal | exitvals.CheckError
+	case zerolog.InfoLevel:
+		exitVal &= exitvals.CheckFatal | exitvals.CheckError | exitvals.CheckWarn
+	case zerolog.DebugLevel:
+		exitVal &= exitvals.CheckFatal | exitvals.CheckError | exitvals.CheckWarn | exitvals.CheckInfo
+	default:
+		// none
+	}
+	return exitVal
+}
+
+// readTemplateFile opens and decodes a template without checking it.
+func readTemplateFile(conf *libdctlint.Conf, fileName string) (internal.Template, exitvals.CheckSeverity) {
+	conf.SetFilename(fileName)
+	f, err := os.Open(fileName)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		return internal.Template{}, exitvals.CheckError
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close file")
+		}
+	}()
+	return conf.ReadTemplate(bufio.NewReader(f))
+}
+
+func main() {
+	initLogging()
+
+	if 1 < len(os.Args) {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(int(cmd(os.Args[2:])))
+		}
+	}
 
 	exitVal := exitvals.CheckOK
-	conf := getRuntimeConf()
+	conf, mode := getRuntimeConf()
 
 	if flag.NArg() < 1 {
 		log.Debug().Msg("reading from stdin")
 		conf.SetFilename("/dev/stdin")
 		reader := bufio.NewReader(os.Stdin)
-		exitVal = conf.CheckTemplate(reader)
+		exitVal = mode.check(reader)
 	} else {
 		for _, arg := range flag.Args() {
 			conf.SetFilename(arg)
@@ -121,7 +254,7 @@ func main() {
 				continue
 			}
 			log.Debug().Str("template", arg).Msg("processing template")
-			exitVal |= conf.CheckTemplate(bufio.NewReader(f))
+			exitVal |= mode.check(bufio.NewReader(f))
 			err = f.Close()
 			if err != nil {
 				log.Error().Err(err).Msg("could not close file")
@@ -130,20 +263,9 @@ func main() {
 		}
 	}
 
-	switch conf.GetToleration() {
-	case zerolog.Disabled:
-		exitVal = exitvals.CheckOK
-	case zerolog.ErrorLevel:
-		exitVal &= exitvals.CheckFatal
-	case zerolog.WarnLevel:
-		exitVal &= exitvals.CheckFatal | exitvals.CheckError
-	case zerolog.InfoLevel:
-		exitVal &= exitvals.CheckFatal | exitvals.CheckError | exitvals.CheckWarn
-	case zerolog.DebugLevel:
-		exitVal &= exitvals.CheckFatal | exitvals.CheckError | exitvals.CheckWarn | exitvals.CheckInfo
-	default:
-		// none
+	if mode.finish != nil {
+		exitVal |= mode.finish()
 	}
 
-	os.Exit(int(exitVal))
+	os.Exit(int(tolerate(conf.GetToleration(), exitVal)))
 }
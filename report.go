@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// dctlWikiURL is where long DCTL explanations are, the code is appended.
+const dctlWikiURL = "https://github.com/Domain-Connect/dc-template-linter/wiki/"
+
+// reportFinding is a lint message shown on a template page.
+type reportFinding struct {
+	Code    string
+	Level   string
+	Note    string
+	Details string
+	Link    string
+}
+
+// reportRecord is a template record row.
+type reportRecord struct {
+	Type    string
+	GroupID string
+	Host    string
+	Value   string
+	TTL     string
+	Extra   string
+}
+
+// reportPage is the content of a template page.
+type reportPage struct {
+	Page                string
+	Entry               libdctlint.CatalogEntry
+	Description         string
+	VariableDescription string
+	SyncPubKeyDomain    string
+	SyncRedirectDomain  string
+	Records             []reportRecord
+	Findings            []reportFinding
+}
+
+// reportProvider groups template pages on the index page.
+type reportProvider struct {
+	ProviderID   string
+	ProviderName string
+	Pages        []*reportPage
+}
+
+// ignoredFindingFields are message fields shown elsewhere on the page.
+var ignoredFindingFields = []string{"level", "time", "code", "dctl_note", "template", "message"}
+
+// newReportFinding converts a captured DCTL message to a finding.
+func newReportFinding(msg libdctlint.DCTLMessage) reportFinding {
+	finding := reportFinding{
+		Code:  msg.Code.String(),
+		Level: msg.Level.String(),
+		Note:  msg.Code.Description(),
+		Link:  dctlWikiURL + msg.Code.String(),
+	}
+	var fields map[string]any
+	if json.Unmarshal([]byte(msg.Message), &fields) != nil {
+		return finding
+	}
+	var details []string
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if !slices.Contains(ignoredFindingFields, name) {
+			details = append(details, fmt.Sprintf("%s=%v", name, fields[name]))
+		}
+	}
+	finding.Details = strings.Join(details, ", ")
+	return finding
+}
+
+// pageStatus returns the most severe finding level, or ok.
+func pageStatus(findings []reportFinding) string {
+	status := zerolog.NoLevel
+	for _, finding := range findings {
+		level, err := zerolog.ParseLevel(finding.Level)
+		if err == nil && (status == zerolog.NoLevel || status < level) {
+			status = level
+		}
+	}
+	if status == zerolog.NoLevel {
+		return "ok"
+	}
+	return status.String()
+}
+
+// newReportRecord returns the table row of a template record.
+func newReportRecord(record internal.Record) reportRecord {
+	row := reportRecord{
+		Type:    record.Type,
+		GroupID: record.GroupID,
+		Host:    record.Host,
+		TTL:     string(record.TTL),
+	}
+	for _, value := range []string{record.PointsTo, record.Target, record.Data, record.SPFRules} {
+		if value != "" {
+			row.Value = value
+			break
+		}
+	}
+	var extra []string
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"name", record.Name},
+		{"service", record.Service},
+		{"protocol", record.Protocol},
+		{"priority", string(record.Priority)},
+		{"weight", string(record.Weight)},
+		{"port", string(record.Port)},
+		{"essential", record.Essential},
+		{"txtConflictMatchingMode", record.TxtCMM},
+		{"txtConflictMatchingPrefix", record.TxtCMP},
+	} {
+		if field.value != "" {
+			extra = append(extra, field.name+"="+field.value)
+		}
+	}
+	row.Extra = strings.Join(extra, " ")
+	return row
+}
+
+// pageName returns a unique page name based on the template file name.
+func pageName(fileName string, used map[string]bool) string {
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	name := base
+	for i := 2; used[name] || name == "index"; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+const reportHTMLHead = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.fatal, .error { color: #b00; }
+.warn { color: #a60; }
+</style>
+</head>
+<body>
+`
+
+var reportIndexHTML = htmltemplate.Must(htmltemplate.New("index").Parse(reportHTMLHead + `<h1>Templates</h1>
+{{range .Providers}}<h2>{{.ProviderName}} ({{.ProviderID}})</h2>
+<ul>
+{{range .Pages}}<li><a href="{{.Page}}.html">{{.Entry.ServiceName}}</a> ({{.Entry.ServiceID}}) <span class="{{.Entry.Status}}">{{.Entry.Status}}</span></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+var reportPageHTML = htmltemplate.Must(htmltemplate.New("page").Parse(reportHTMLHead + `<p><a href="index.html">Templates</a></p>
+<h1>{{.Entry.ServiceName}}</h1>
+<table>
+<tr><th>providerId</th><td>{{.Entry.ProviderID}}</td></tr>
+<tr><th>providerName</th><td>{{.Entry.ProviderName}}</td></tr>
+<tr><th>serviceId</th><td>{{.Entry.ServiceID}}</td></tr>
+<tr><th>version</th><td>{{.Entry.Version}}</td></tr>
+<tr><th>file</th><td>{{.Entry.File}}</td></tr>
+<tr><th>status</th><td class="{{.Entry.Status}}">{{.Entry.Status}}</td></tr>
+{{if .Entry.Logo}}<tr><th>logoUrl</th><td><a href="{{.Entry.Logo}}">logo preview</a></td></tr>
+{{end}}{{if .Entry.Flags}}<tr><th>flags</th><td>{{range $i, $f := .Entry.Flags}}{{if $i}}, {{end}}{{$f}}{{end}}</td></tr>
+{{end}}{{if .SyncPubKeyDomain}}<tr><th>syncPubKeyDomain</th><td>{{.SyncPubKeyDomain}}</td></tr>
+{{end}}{{if .SyncRedirectDomain}}<tr><th>syncRedirectDomain</th><td>{{.SyncRedirectDomain}}</td></tr>
+{{end}}{{if .Description}}<tr><th>description</th><td>{{.Description}}</td></tr>
+{{end}}</table>
+<h2>Records</h2>
+<table>
+<tr><th>type</th><th>groupId</th><th>host</th><th>value</th><th>ttl</th><th>other</th></tr>
+{{range .Records}}<tr><td>{{.Type}}</td><td>{{.GroupID}}</td><td>{{.Host}}</td><td>{{.Value}}</td><td>{{.TTL}}</td><td>{{.Extra}}</td></tr>
+{{end}}</table>
+<h2>Variables</h2>
+{{if .Entry.Variables}}<ul>
+{{range .Entry.Variables}}<li>{{.}}</li>
+{{end}}</ul>
+{{if .VariableDescription}}<p>{{.VariableDescription}}</p>
+{{end}}{{else}}<p>None</p>
+{{end}}<h2>Findings</h2>
+{{if .Findings}}<table>
+<tr><th>code</th><th>level</th><th>note</th><th>details</th></tr>
+{{range .Findings}}<tr><td><a href="{{.Link}}">{{.Code}}</a></td><td class="{{.Level}}">{{.Level}}</td><td>{{.Note}}</td><td>{{.Details}}</td></tr>
+{{end}}</table>
+{{else}}<p>None</p>
+{{end}}</body>
+</html>
+`))
+
+// mdEscape makes a value safe for a markdown table cell.
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+var reportFuncs = template.FuncMap{"md": mdEscape}
+
+var reportIndexMarkdown = template.Must(template.New("index").Funcs(reportFuncs).Parse(`# Templates
+{{range .Providers}}
+## {{md .ProviderName}} ({{md .ProviderID}})
+
+{{range .Pages}}- [{{md .Entry.ServiceName}}]({{.Page}}.md) ({{md .Entry.ServiceID}}) {{.Entry.Status}}
+{{end}}{{end}}`))
+
+var reportPageMarkdown = template.Must(template.New("page").Funcs(reportFuncs).Parse(`[Templates](index.md)
+
+# {{md .Entry.ServiceName}}
+
+| field | value |
+|---|---|
+| providerId | {{md .Entry.ProviderID}} |
+| providerName | {{md .Entry.ProviderName}} |
+| serviceId | {{md .Entry.ServiceID}} |
+| version | {{.Entry.Version}} |
+| file | {{md .Entry.File}} |
+| status | {{.Entry.Status}} |
+{{if .Entry.Logo}}| logoUrl | [logo preview]({{.Entry.Logo}}) |
+{{end}}{{if .Entry.Flags}}| flags | {{range $i, $f := .Entry.Flags}}{{if $i}}, {{end}}{{$f}}{{end}} |
+{{end}}{{if .SyncPubKeyDomain}}| syncPubKeyDomain | {{md .SyncPubKeyDomain}} |
+{{end}}{{if .SyncRedirectDomain}}| syncRedirectDomain | {{md .SyncRedirectDomain}} |
+{{end}}{{if .Description}}| description | {{md .Description}} |
+{{end}}
+## Records
+
+| type | groupId | host | value | ttl | other |
+|---|---|---|---|---|---|
+{{range .Records}}| {{md .Type}} | {{md .GroupID}} | {{md .Host}} | {{md .Value}} | {{md .TTL}} | {{md .Extra}} |
+{{end}}
+## Variables
+
+{{if .Entry.Variables}}{{range .Entry.Variables}}- {{md .}}
+{{end}}{{if .VariableDescription}}
+{{md .VariableDescription}}
+{{end}}{{else}}None
+{{end}}
+## Findings
+
+{{if .Findings}}| code | level | note | details |
+|---|---|---|---|
+{{range .Findings}}| [{{.Code}}]({{.Link}}) | {{.Level}} | {{md .Note}} | {{md .Details}} |
+{{end}}{{else}}None
+{{end}}`))
+
+// reportExecutor is satisfied by both html and text templates.
+type reportExecutor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// writeReportFile executes a template to a file in the output directory.
+func writeReportFile(dir, name string, tmpl reportExecutor, data any) error {
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = tmpl.Execute(w, data)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// reportCommand lints templates, and writes an html and markdown page of
+// each template and an index of providers to a directory.
+func reportCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s report [options] <template.json> [...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	outDir := fs.String("out", "", "directory to write the report to")
+	lint := addLintFlags(fs)
+	_ = fs.Parse(args)
+
+	if fs.NArg() < 1 || *outDir == "" {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	catalog := libdctlint.NewCatalog()
+	conf := lint.conf().
+		SetLib(true).
+		SetCatalog(catalog)
+
+	exitVal := exitvals.CheckOK
+	pages := make(map[string]*reportPage)
+	var inputs []string
+	used := make(map[string]bool)
+	for _, arg := range fs.Args() {
+		conf.SetFilename(arg)
+		f, err := os.Open(arg)
+		if err != nil {
+			log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+			exitVal |= exitvals.CheckError
+			continue
+		}
+		template, checkVal := conf.GetAndCheckTemplate(bufio.NewReader(f))
+		exitVal |= checkVal
+		if err := f.Close(); err != nil {
+			log.Error().Err(err).Msg("could not close file")
+			exitVal |= exitvals.CheckFatal
+		}
+		var findings []reportFinding
+		for _, msg := range conf.GetMessages() {
+			findings = append(findings, newReportFinding(msg))
+		}
+		page := &reportPage{
+			Page:                pageName(arg, used),
+			Description:         template.Description,
+			VariableDescription: template.VariableDescription,
+			SyncPubKeyDomain:    template.SyncPubKeyDomain,
+			SyncRedirectDomain:  template.SyncRedirectDomain,
+			Findings:            findings,
+		}
+		for _, record := range template.Records {
+			page.Records = append(page.Records, newReportRecord(record))
+		}
+		pages[arg] = page
+		inputs = append(inputs, arg)
+	}
+
+	// provider consistency findings refer to templates by file name
+	exitVal |= conf.CheckProviders()
+	for _, msg := range conf.GetMessages() {
+		var fields struct {
+			Template string `json:"template"`
+		}
+		if json.Unmarshal([]byte(msg.Message), &fields) != nil {
+			continue
+		}
+		if page, ok := pages[fields.Template]; ok {
+			page.Findings = append(page.Findings, newReportFinding(msg))
+		}
+	}
+
+	// catalog entries, with duplicates, give the pages their metadata
+	var providers []*reportProvider
+	byProvider := make(map[string]*reportProvider)
+	for _, entry := range catalog.Templates {
+		for _, file := range append([]string{entry.File}, entry.DuplicateFiles...) {
+			page, ok := pages[file]
+			if !ok {
+				continue
+			}
+			page.Entry = entry
+			page.Entry.File = file
+			page.Entry.Status = pageStatus(page.Findings)
+			page.Entry.DuplicateFiles = nil
+			provider, ok := byProvider[entry.ProviderID]
+			if !ok {
+				provider = &reportProvider{ProviderID: entry.ProviderID, ProviderName: entry.ProviderName}
+				byProvider[entry.ProviderID] = provider
+				providers = append(providers, provider)
+			}
+			provider.Pages = append(provider.Pages, page)
+		}
+	}
+	// inputs that could not be decoded have no catalog entry, report the
+	// reasons on the console
+	for _, arg := range inputs {
+		page := pages[arg]
+		if page.Entry.ID != "" {
+			continue
+		}
+		for _, finding := range page.Findings {
+			level, err := zerolog.ParseLevel(finding.Level)
+			if err != nil {
+				level = zerolog.ErrorLevel
+			}
+			log.WithLevel(level).Str("template", arg).Str("code", finding.Code).Str("details", finding.Details).Msg(finding.Note)
+		}
+	}
+	slices.SortFunc(providers, func(a, b *reportProvider) int {
+		return strings.Compare(a.ProviderID, b.ProviderID)
+	})
+	for _, provider := range providers {
+		slices.SortStableFunc(provider.Pages, func(a, b *reportPage) int {
+			return strings.Compare(a.Entry.ServiceID, b.Entry.ServiceID)
+		})
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+		return exitVal | exitvals.CheckError
+	}
+	index := struct {
+		Title     string
+		Providers []*reportProvider
+	}{"Templates", providers}
+	err := writeReportFile(*outDir, "index.html", reportIndexHTML, index)
+	if err == nil {
+		err = writeReportFile(*outDir, "index.md", reportIndexMarkdown, index)
+	}
+	written := 0
+	for _, provider := range providers {
+		for _, page := range provider.Pages {
+			if err != nil {
+				break
+			}
+			data := struct {
+				Title string
+				*reportPage
+			}{page.Entry.ServiceName, page}
+			err = writeReportFile(*outDir, page.Page+".html", reportPageHTML, data)
+			if err == nil {
+				err = writeReportFile(*outDir, page.Page+".md", reportPageMarkdown, data)
+			}
+			if err == nil {
+				written++
+			}
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+		return exitVal | exitvals.CheckError
+	}
+	log.Info().Str("out", *outDir).Int("templates", written).Int("providers", len(providers)).Msg("report written")
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
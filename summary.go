@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newSummaryMode writes the consent summary of each template.
+func newSummaryMode(conf *libdctlint.Conf, catalog *libdctlint.SummaryCatalog) runMode {
+	first := true
+
+	return runMode{
+		check: func(f *bufio.Reader) exitvals.CheckSeverity {
+			template, exitVal := conf.ReadTemplate(f)
+			if exitVal != exitvals.CheckOK {
+				return exitVal
+			}
+			lines := libdctlint.Summarize(template, catalog)
+			var sb strings.Builder
+			if !first {
+				sb.WriteString("\n")
+			}
+			first = false
+			sb.WriteString(lines[0] + "\n")
+			for _, line := range lines[1:] {
+				sb.WriteString("- " + line + "\n")
+			}
+			if _, err := fmt.Print(sb.String()); err != nil {
+				log.Error().Err(err).EmbedObject(internal.DCTL0004).Msg("")
+				return exitvals.CheckError
+			}
+			return exitvals.CheckOK
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// testCommand runs template companion test files.
+func testCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s test [options] <template.json> [...]\n", os.Args[0])
+		_, _ = fmt.Fprintf(os.Stderr, "Test file <providerId>.<serviceId>.test.json is looked up from the template directory\n")
+		fs.PrintDefaults()
+	}
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	exitVal := exitvals.CheckOK
+	for _, arg := range fs.Args() {
+		if strings.HasSuffix(arg, ".test.json") {
+			continue
+		}
+		template, ev := readTemplateFile(conf, arg)
+		if ev != exitvals.CheckOK {
+			exitVal |= ev
+			continue
+		}
+		exitVal |= runTestFile(conf, template, arg)
+	}
+
+	return tolerate(conf.GetToleration(), exitVal)
+}
+
+func runTestFile(conf *libdctlint.Conf, template internal.Template, templatePath string) exitvals.CheckSeverity {
+	testPath := libdctlint.TemplateTestFile(template, templatePath)
+	f, err := os.Open(testPath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.WithLevel(internal.DCTL2200.Level()).Str("template", templatePath).
+			Str("testfile", testPath).EmbedObject(internal.DCTL2200).Msg("")
+		return internal.DCTL2200.Severity()
+	}
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		return exitvals.CheckError
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close file")
+		}
+	}()
+
+	log.Debug().Str("template", templatePath).Str("testfile", testPath).Msg("processing test file")
+	conf.SetFilename(templatePath)
+	return conf.RunTemplateTests(template, bufio.NewReader(f))
+}
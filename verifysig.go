@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Domain-Connect/dc-template-linter/exitvals"
+	"github.com/Domain-Connect/dc-template-linter/internal"
+	"github.com/Domain-Connect/dc-template-linter/libdctlint"
+
+	"github.com/rs/zerolog/log"
+)
+
+// verifySigCommand checks a synchronous apply request signature offline.
+func verifySigCommand(args []string) exitvals.CheckSeverity {
+	fs := flag.NewFlagSet("verify-sig", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: %s verify-sig [options] <template.json>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	query := fs.String("query", "", "apply query string or full apply url")
+	sig := fs.String("sig", "", "sig parameter, default is to use value from the query")
+	key := fs.String("key", "", "key parameter, default is to use value from the query")
+	pubKey := fs.String("pubkey", "", "file containing PEM public key or syncPubKeyDomain TXT record content")
+	loglevel := fs.String("loglevel", "info", "loglevel can be one of: panic fatal error warn info debug trace")
+	toleration := fs.String("tolerate", "info", "non-zero return loglevel threshold: any error warn info debug none")
+	_ = fs.Parse(args)
+
+	internal.SetLoglevel(*loglevel)
+
+	if fs.NArg() != 1 || *query == "" || *pubKey == "" {
+		fs.Usage()
+		return exitvals.CheckFatal
+	}
+
+	keyData, err := os.ReadFile(*pubKey)
+	if err != nil {
+		log.Error().Err(err).EmbedObject(internal.DCTL0001).Msg("")
+		return exitvals.CheckError
+	}
+
+	conf := libdctlint.NewConf().SetToleration(*toleration)
+	template, exitVal := readTemplateFile(conf, fs.Arg(0))
+	if exitVal != exitvals.CheckOK {
+		return exitVal
+	}
+	exitVal = conf.VerifyApplySignature(template, *query, *sig, *key, keyData)
+
+	return tolerate(conf.GetToleration(), exitVal)
+}